@@ -3,42 +3,119 @@ package main
 import (
 	"flag"
 	"fmt"
+	goast "go/ast"
 	"io/ioutil"
 	"os"
 	"strings"
 
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
 	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
 )
 
 func main() {
 	analyzeCmd := flag.NewFlagSet("analyze", flag.ExitOnError)
 	filePath := analyzeCmd.String("file", "", "Path to the Go file to analyze")
+	dirPath := analyzeCmd.String("dir", "", "Package directory to analyze; a trailing /... analyzes every package below it")
 	rulesStr := analyzeCmd.String("rules", "", "Comma-separated list of rules to check")
+	typeCheck := analyzeCmd.Bool("types", false, "Type-check the file for type-aware analysis")
+	rulesDir := analyzeCmd.String("rules-dir", "./server/mcpserver/rules", "Path to YAML rules used to resolve aliases and categories")
+	format := analyzeCmd.String("format", "text", "Output format: "+strings.Join(report.Formats(), ", "))
+	output := analyzeCmd.String("output", "", "File to write the report to (default: stdout)")
 
 	if len(os.Args) < 2 {
-		fmt.Println("Expected 'analyze' subcommand")
+		fmt.Println("Expected 'analyze' or 'list' subcommand")
 		os.Exit(1)
 	}
 
 	switch os.Args[1] {
 	case "analyze":
 		analyzeCmd.Parse(os.Args[2:])
-		if *filePath == "" {
-			fmt.Println("--file flag is required")
+		if (*filePath == "") == (*dirPath == "") {
+			fmt.Println("exactly one of --file or --dir is required")
 			os.Exit(1)
 		}
 		if *rulesStr == "" {
 			fmt.Println("--rules flag is required")
 			os.Exit(1)
 		}
-		runAnalysis(*filePath, *rulesStr)
+		if _, err := report.New(*format); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		runAnalysis(*filePath, *dirPath, *rulesStr, *rulesDir, *typeCheck, *format, *output)
+	case "list":
+		listRules()
 	default:
 		fmt.Printf("Unknown subcommand: %s\n", os.Args[1])
 		os.Exit(1)
 	}
 }
 
-func runAnalysis(filePath, rulesStr string) {
+func runAnalysis(filePath, dirPath, rulesStr, rulesDir string, typeCheck bool, format, output string) {
+	// Initialize the analyzer
+	config := ast.AnalyzerConfig{
+		IncludeTests: true,
+		TypeCheck:    typeCheck,
+	}
+	astAnalyzer := ast.NewAnalyzer(config)
+
+	var passes []*analyzer.Pass
+	if filePath != "" {
+		passes = []*analyzer.Pass{parseFile(astAnalyzer, filePath)}
+	} else {
+		passes = parsePackages(astAnalyzer, dirPath)
+	}
+
+	// Split the rules string
+	rules := strings.Split(rulesStr, ",")
+	registry := loadRegistry(rulesDir)
+
+	// Run the registered analysis for each rule
+	issues := make(map[string][]ast.Issue)
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+
+		ids := []string{rule}
+		if registry != nil {
+			if resolved := registry.Resolve(rule); len(resolved) > 0 {
+				ids = ids[:0]
+				for _, r := range resolved {
+					ids = append(ids, r.ID)
+				}
+			}
+		}
+
+		known := false
+		for _, id := range ids {
+			if r, ok := analyzer.Lookup(id); ok {
+				known = true
+				for _, pass := range passes {
+					issues[pass.Filename] = append(issues[pass.Filename], r.Check(pass)...)
+				}
+			}
+		}
+		if !known {
+			fmt.Fprintf(os.Stderr, "Unknown rule: %s\n", rule)
+		}
+	}
+
+	// Report the results
+	result := &report.Result{}
+	for _, pass := range passes {
+		result.Files = append(result.Files, report.FileResult{Path: pass.Filename, Issues: issues[pass.Filename]})
+	}
+	if registry != nil {
+		result.Rules = registry.Rules()
+	}
+	if err := report.Write(output, format, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseFile(astAnalyzer *ast.Analyzer, filePath string) *analyzer.Pass {
 	// Read the file
 	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -46,54 +123,77 @@ func runAnalysis(filePath, rulesStr string) {
 		os.Exit(1)
 	}
 
-	// Initialize the analyzer
-	config := ast.AnalyzerConfig{
-		IncludeTests: true,
-	}
-	analyzer := ast.NewAnalyzer(config)
-
 	// Parse the file
-	file, err := analyzer.ParseFile(filePath, content)
+	file, err := astAnalyzer.ParseFile(filePath, content)
 	if err != nil {
 		fmt.Printf("Error parsing file: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Split the rules string
-	rules := strings.Split(rulesStr, ",")
+	return &analyzer.Pass{
+		Analyzer: astAnalyzer,
+		Filename: filePath,
+		File:     file,
+		Src:      content,
+		Files:    []*goast.File{file},
+	}
+}
 
-	// Run the appropriate analysis for each rule
-	for _, rule := range rules {
-		rule = strings.TrimSpace(rule)
-		var issues []ast.Issue
-
-		switch rule {
-		case "error_handling":
-			issues = analyzer.AnalyzeErrorHandling(file)
-		case "api_design":
-			issues = analyzer.AnalyzeAPIDesign(file)
-		case "concurrent_map_access", "synchronization":
-			issues = analyzer.AnalyzeConcurrencySafety(file)
-		case "secure_coding":
-			issues = analyzer.AnalyzeSecurityIssues(file)
-		case "org_coding_standards", "coding_standards":
-			issues = analyzer.AnalyzeOrganizationStandards(file)
-		default:
-			fmt.Printf("Unknown rule: %s\n", rule)
-			continue
+// parsePackages returns one pass per file of the packages in dirPath, each
+// carrying the other files of its package.
+func parsePackages(astAnalyzer *ast.Analyzer, dirPath string) []*analyzer.Pass {
+	dirs := []string{dirPath}
+	if root, ok := strings.CutSuffix(dirPath, "/..."); ok {
+		var err error
+		if dirs, err = ast.PackageDirs(root); err != nil {
+			fmt.Printf("Error listing packages: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var passes []*analyzer.Pass
+	for _, dir := range dirs {
+		pkgs, err := astAnalyzer.ParsePackages(dir)
+		if err != nil {
+			fmt.Printf("Error parsing package: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Print the results
-		fmt.Printf("Rule: %s\n", rule)
-		if len(issues) == 0 {
-			fmt.Println("  No issues found")
-		} else {
-			for i, issue := range issues {
-				fmt.Printf("  Issue %d:\n", i+1)
-				fmt.Printf("    Description: %s\n", issue.Description)
-				fmt.Printf("    Severity: %s\n", issue.Severity)
-				fmt.Printf("    Location: Line %d, Column %d\n", issue.Position.Line, issue.Position.Column)
+		for _, pkg := range pkgs {
+			files := pkg.ASTFiles()
+			for _, f := range pkg.Files {
+				passes = append(passes, &analyzer.Pass{
+					Analyzer: astAnalyzer,
+					Filename: f.Path,
+					File:     f.AST,
+					Src:      f.Src,
+					Files:    files,
+				})
 			}
 		}
 	}
+
+	return passes
+}
+
+// loadRegistry returns the YAML rule registry used to resolve aliases and
+// categories, or nil if the rules directory is not available.
+func loadRegistry(rulesDir string) *rules.RuleRegistry {
+	if _, err := os.Stat(rulesDir); err != nil {
+		return nil
+	}
+
+	registry, err := rules.NewRuleRegistry(rulesDir)
+	if err != nil {
+		fmt.Printf("Error loading rules: %v\n", err)
+		os.Exit(1)
+	}
+	return registry
+}
+
+func listRules() {
+	for _, rule := range analyzer.Rules() {
+		meta := rule.Meta()
+		fmt.Printf("%-24s %-8s %-14s %s\n", rule.ID(), meta.Severity, meta.Category, meta.Description)
+	}
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+)
+
+// auditRules reports drift between the YAML rules and the analyses
+// registered with the engine. The report is printed and, with -output, also
+// written as JSON for CI gating. The command exits non-zero on any drift.
+func auditRules(cfg cliConfig) {
+	registry := loadRegistry(cfg)
+	if registry == nil {
+		log.Fatalf("Rules directory not found: %s", cfg.rulesDir)
+	}
+
+	fmt.Println("Auditing rules for enforcement drift...")
+	fmt.Printf("Checking rules in %s against registered analyses\n", cfg.rulesDir)
+
+	report := analyzer.Audit(registry)
+
+	for _, d := range report.Drift {
+		location := d.RuleID
+		if d.Path != "" {
+			location = fmt.Sprintf("%s (%s)", d.RuleID, d.Path)
+		}
+		fmt.Printf("  - [%s] %s: %s\n", d.Kind, location, d.Message)
+	}
+	fmt.Printf("%d rule(s), %d analysis(es), %d drift finding(s)\n", report.Rules, report.Analyses, len(report.Drift))
+
+	if cfg.outputFile != "" && cfg.outputFile != "-" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode audit report: %v", err)
+		}
+		if err := os.WriteFile(cfg.outputFile, append(data, '\n'), 0644); err != nil {
+			log.Fatalf("Failed to write audit report: %v", err)
+		}
+		fmt.Printf("Results written to %s\n", cfg.outputFile)
+	}
+
+	if len(report.Drift) > 0 {
+		os.Exit(1)
+	}
+}
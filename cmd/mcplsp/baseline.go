@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/baseline"
+)
+
+// defaultBaselineFile is where baseline create writes by default.
+const defaultBaselineFile = ".mcplsp-baseline.json"
+
+func manageBaseline(cfg cliConfig) {
+	args := flag.Args()
+	if len(args) < 2 || args[1] != "create" {
+		log.Fatal("Usage: mcplsp [flags] baseline create [-o file] <path>... [rule1,rule2,...]")
+	}
+	createBaseline(cfg, args[2:])
+}
+
+// createBaseline validates the targets and records their findings, so that a
+// later validate -baseline reports only new ones.
+func createBaseline(cfg cliConfig, args []string) {
+	createCmd := flag.NewFlagSet("baseline create", flag.ExitOnError)
+	output := createCmd.String("o", defaultBaselineFile, "Baseline file to write")
+	createCmd.Parse(args)
+
+	args = createCmd.Args()
+	if len(args) == 0 {
+		log.Fatal("Missing files to baseline")
+	}
+
+	registry := loadRegistry(cfg)
+	ruleIDs, args := ruleArgs(registry, args)
+
+	files, err := expandTargets(args)
+	if err != nil {
+		log.Fatalf("Failed to resolve files: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatal("No Go files to baseline")
+	}
+
+	results := collectResults(cfg, registry, files, ruleIDs, func(format string, args ...interface{}) {
+		fmt.Printf(format, args...)
+	})
+	for _, f := range results {
+		if f.Err != nil {
+			log.Printf("Skipping %s: %v", f.Path, f.Err)
+		}
+	}
+
+	b, err := baseline.New(results)
+	if err != nil {
+		log.Fatalf("Failed to create baseline: %v", err)
+	}
+	if err := b.Write(*output); err != nil {
+		log.Fatal(err)
+	}
+
+	findings := 0
+	for _, entry := range b.Entries {
+		findings += entry.Count
+	}
+	fmt.Printf("Recorded %d finding(s) from %d file(s) in %s\n", findings, len(files), *output)
+}
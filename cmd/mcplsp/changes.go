@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/diff"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+)
+
+// loadChanges returns the lines added by a unified diff file, or by the
+// working tree since a git revision, along with the directory the diff's
+// paths are relative to.
+func loadChanges(diffFile, since string) (diff.Changes, string, error) {
+	if diffFile != "" {
+		f, err := os.Open(diffFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open diff: %w", err)
+		}
+		defer f.Close()
+
+		changes, err := diff.Parse(f)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse diff %s: %w", diffFile, err)
+		}
+		root, err := os.Getwd()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		return changes, root, nil
+	}
+
+	out, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, "", err
+	}
+	root := strings.TrimSpace(string(out))
+
+	out, err = git("-C", root, "diff", "--no-color", "--no-ext-diff", "-U0", since, "--")
+	if err != nil {
+		return nil, "", err
+	}
+	changes, err := diff.Parse(bytes.NewReader(out))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse git diff: %w", err)
+	}
+	return changes, root, nil
+}
+
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// filterChanged keeps only the issues on lines the diff added. Files that
+// could not be validated are kept as they are.
+func filterChanged(results []report.FileResult, changes diff.Changes, root string) []report.FileResult {
+	filtered := make([]report.FileResult, 0, len(results))
+	for _, f := range results {
+		if f.Err != nil {
+			filtered = append(filtered, f)
+			continue
+		}
+
+		kept := report.FileResult{Path: f.Path}
+		for _, issue := range f.Issues {
+			file := issue.Position.Filename
+			if file == "" {
+				file = f.Path
+			}
+			if changes.Contains(relativeTo(root, file), issue.Position.Line) {
+				kept.Issues = append(kept.Issues, issue)
+			}
+		}
+		filtered = append(filtered, kept)
+	}
+	return filtered
+}
+
+// relativeTo returns the slash-separated path of file relative to root.
+func relativeTo(root, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	// git reports the resolved top level, so resolve links such as a
+	// symlinked temp directory on both sides
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
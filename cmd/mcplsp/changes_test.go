@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/diff"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+)
+
+func TestFilterChanged(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "pkg", "a.go")
+	issue := func(line int) ast.Issue {
+		return ast.Issue{RuleID: "error_handling", Position: token.Position{Filename: path, Line: line}}
+	}
+
+	results := filterChanged([]report.FileResult{
+		{Path: path, Issues: []ast.Issue{issue(3), issue(4), issue(9)}},
+		{Path: filepath.Join(root, "b.go"), Err: errors.New("failed to parse file")},
+	}, diff.Changes{"pkg/a.go": {{Start: 4, End: 5}, {Start: 9, End: 9}}}, root)
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	var lines []int
+	for _, issue := range results[0].Issues {
+		lines = append(lines, issue.Position.Line)
+	}
+	if len(lines) != 2 || lines[0] != 4 || lines[1] != 9 {
+		t.Errorf("Expected issues on lines 4 and 9, got %v", lines)
+	}
+	if results[1].Err == nil {
+		t.Error("Expected the file error to be kept")
+	}
+}
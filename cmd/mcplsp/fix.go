@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/diff"
+)
+
+// refactorRules are the rules whose fixes change a declaration and its
+// references, such as a method's signature or a function's name. Code the
+// fixes cannot see may still use the old declaration, so they only run when
+// named explicitly.
+var refactorRules = map[string]bool{
+	"api_design":           true,
+	"org_coding_standards": true,
+}
+
+// fix applies the analyzers' suggested fixes to the given files, printing
+// them as unified diffs or writing them back.
+func fix(cfg cliConfig) {
+	fixCmd := flag.NewFlagSet("fix", flag.ExitOnError)
+	showDiff := fixCmd.Bool("diff", false, "Print the fixes as unified diffs (default)")
+	write := fixCmd.Bool("write", false, "Write the fixes back to the files")
+	fixCmd.Parse(flag.Args()[1:])
+
+	if *showDiff && *write {
+		log.Fatal("Usage: mcplsp [flags] fix [-diff|-write] <path>... [rule1,rule2,...]")
+	}
+
+	args := fixCmd.Args()
+	if len(args) == 0 {
+		log.Fatal("Missing files to fix")
+	}
+
+	registry := loadRegistry(cfg)
+	var ruleIDs []string
+	for _, rule := range analyzer.Rules() {
+		if !refactorRules[rule.ID()] {
+			ruleIDs = append(ruleIDs, rule.ID())
+		}
+	}
+	// A trailing argument that names no files is the list of rules
+	if last := args[len(args)-1]; len(args) > 1 && !isTargetArg(last) {
+		ruleIDs = strings.Split(last, ",")
+		args = args[:len(args)-1]
+	}
+
+	files, err := expandTargets(args)
+	if err != nil {
+		log.Fatalf("Failed to resolve files: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatal("No Go files to fix")
+	}
+
+	engine, err := analyzer.NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: cfg.typeCheck}, registry)
+	if err != nil {
+		log.Fatalf("Failed to create analyzer: %v", err)
+	}
+
+	fixedFiles, fixed, err := engine.FixFiles(files, ruleIDs)
+	if err != nil {
+		log.Fatalf("Failed to fix files: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, issue := range fixed {
+		counts[issue.Position.Filename]++
+	}
+
+	for _, path := range files {
+		fixedSrc, ok := fixedFiles[filepath.Clean(path)]
+		if !ok {
+			continue
+		}
+
+		if !*write {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				log.Fatalf("Failed to read file: %v", err)
+			}
+			os.Stdout.Write(diff.Unified(path+".orig", path, src, fixedSrc))
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Fatalf("Failed to stat file: %v", err)
+		}
+		if err := os.WriteFile(path, fixedSrc, info.Mode().Perm()); err != nil {
+			log.Fatalf("Failed to write file: %v", err)
+		}
+		fmt.Printf("%s: applied %d fix(es)\n", path, counts[filepath.Clean(path)])
+	}
+
+	if *write {
+		fmt.Printf("Applied %d fix(es) in %d file(s)\n", len(fixed), len(fixedFiles))
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
 	"github.com/yourorg/go-mcp-lsp/pkg/mcpclient"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
 )
 
 type cliConfig struct {
@@ -19,6 +21,9 @@ type cliConfig struct {
 	outputFile    string
 	command       string
 	deep          bool
+	typeCheck     bool
+	parallel      int
+	format        string
 }
 
 func main() {
@@ -26,11 +31,21 @@ func main() {
 
 	switch cfg.command {
 	case "validate":
-		validateFile(cfg)
+		validate(cfg)
 	case "audit":
 		auditRules(cfg)
 	case "test":
 		testConnection(cfg)
+	case "rules":
+		manageRules(cfg)
+	case "fix":
+		fix(cfg)
+	case "baseline":
+		manageBaseline(cfg)
+	case "suppressions":
+		listSuppressions(cfg)
+	case "serve":
+		serve(cfg)
 	default:
 		log.Fatalf("Unknown command: %s", cfg.command)
 	}
@@ -40,10 +55,13 @@ func parseFlags() cliConfig {
 	var cfg cliConfig
 
 	flag.StringVar(&cfg.mcpEndpoint, "mcp", "localhost:9000", "MCP server endpoint")
-	flag.StringVar(&cfg.rulesDir, "rules", "./pkg/intent", "Path to intent rules")
+	flag.StringVar(&cfg.rulesDir, "rules", "./server/mcpserver/rules", "Path to intent rules")
 	flag.StringVar(&cfg.mechanismsDir, "mechanisms", "./pkg/mechanism", "Path to enforcement mechanisms")
-	flag.StringVar(&cfg.outputFile, "output", "result.json", "Output file for results")
+	flag.StringVar(&cfg.outputFile, "output", "", "Output file for results (default: stdout)")
 	flag.BoolVar(&cfg.deep, "deep", false, "Use deep AST-based code inspection (default: false)")
+	flag.BoolVar(&cfg.typeCheck, "types", false, "Type-check files during deep inspection (default: false)")
+	flag.IntVar(&cfg.parallel, "j", runtime.NumCPU(), "Number of files or packages to validate in parallel")
+	flag.StringVar(&cfg.format, "format", "text", "Validation output format: "+strings.Join(report.Formats(), ", "))
 
 	flag.Parse()
 
@@ -51,9 +69,16 @@ func parseFlags() cliConfig {
 	if len(args) < 1 {
 		fmt.Println("Usage: mcplsp [flags] <command>")
 		fmt.Println("Commands:")
-		fmt.Println("  validate <file> [rule1,rule2,...] - Validate a Go file against rules")
-		fmt.Println("  audit            - Check for drift between rules and enforcement")
+		fmt.Println("  validate [-baseline file] [-diff file|-since rev] <path>... [rule1,rule2,...] - Validate files, package dirs, dir/... or globs against rules")
+		fmt.Println("  baseline create [-o file] <path>... [rule1,rule2,...] - Record current findings in a baseline file")
+		fmt.Println("  fix [-diff|-write] <path>... [rule1,rule2,...] - Apply suggested fixes, printing diffs or writing files")
+		fmt.Println("  suppressions <path>... - Report unused or malformed //mcplsp:ignore directives")
+		fmt.Println("  audit            - Report drift between YAML rules and registered analyses")
 		fmt.Println("  test             - Test connection to MCP server")
+		fmt.Println("  rules lint [dir] - Validate rule files before shipping them")
+		fmt.Println("  rules list       - List the registered analyses")
+		fmt.Println("  serve --lsp      - Run as a language server on stdin/stdout")
+		fmt.Println("  serve --lsp --proxy - Wrap gopls and add governance to its diagnostics, code actions and hovers")
 		os.Exit(1)
 	}
 
@@ -61,91 +86,63 @@ func parseFlags() cliConfig {
 	return cfg
 }
 
-func validateFile(cfg cliConfig) {
-	if len(flag.Args()) < 2 {
-		log.Fatal("Missing file path to validate")
+// loadRegistry builds the rule registry for the configured rules directory.
+// It returns nil when the directory does not exist so that commands can fall
+// back to the built-in rule ids.
+func loadRegistry(cfg cliConfig) *rules.RuleRegistry {
+	if _, err := os.Stat(cfg.rulesDir); err != nil {
+		return nil
 	}
-
-	filePath := flag.Args()[1]
-	absPath, err := filepath.Abs(filePath)
+	
+	registry, err := rules.NewRuleRegistry(cfg.rulesDir)
 	if err != nil {
-		log.Fatalf("Failed to resolve file path: %v", err)
+		log.Fatalf("Failed to load rules: %v", err)
 	}
+	return registry
+}
 
-	fmt.Printf("Validating file: %s\n", absPath)
+func manageRules(cfg cliConfig) {
+	args := flag.Args()
+	if len(args) < 2 {
+		log.Fatal("Usage: mcplsp rules lint [dir] | mcplsp rules list")
+	}
 	
-	content, err := os.ReadFile(absPath)
-	if err != nil {
-		log.Fatalf("Failed to read file: %v", err)
+	switch args[1] {
+	case "lint":
+		lintRules(cfg)
+	case "list":
+		for _, rule := range analyzer.Rules() {
+			meta := rule.Meta()
+			fmt.Printf("%-24s %-8s %-14s %s\n", rule.ID(), meta.Severity, meta.Category, meta.Description)
+		}
+	default:
+		log.Fatalf("Unknown rules command: %s", args[1])
 	}
+}
 
-	fmt.Printf("Connecting to MCP server at %s\n", cfg.mcpEndpoint)
-	client := mcpclient.New(cfg.mcpEndpoint)
+func lintRules(cfg cliConfig) {
+	args := flag.Args()
 	
-	// Get rule IDs to validate against
-	ruleIDs := []string{"error_handling", "api_design", "concurrent_map_access", "secure_coding", "org_coding_standards"}
-	if len(flag.Args()) > 2 {
-		// Use specific rules if provided
-		ruleArgs := flag.Args()[2]
-		if ruleArgs != "" {
-			ruleIDs = strings.Split(ruleArgs, ",")
-		}
+	rulesDir := cfg.rulesDir
+	if len(args) > 2 {
+		rulesDir = args[2]
 	}
 	
-	fmt.Printf("Validating against rules: %v\n", ruleIDs)
+	problems, err := rules.LintRules(rulesDir)
+	if err != nil {
+		log.Fatalf("Lint failed: %v", err)
+	}
 	
-	if cfg.deep {
-		// Use AST-based analyzer for deeper inspection
-		fmt.Println("Using deep AST-based code inspection...")
-		
-		engine := analyzer.NewAnalyzerEngine()
-		result, err := engine.Analyze(absPath, content, ruleIDs)
-		if err != nil {
-			log.Fatalf("Analysis failed: %v", err)
-		}
-		
-		if result.Valid {
-			fmt.Println("Validation passed!")
-		} else {
-			fmt.Println("Validation failed:")
-			for _, issue := range result.Issues {
-				fmt.Printf("- [%s] %s (Line %d, Col %d) - %s\n", 
-					issue.RuleID, 
-					issue.Description, 
-					issue.Position.Line, 
-					issue.Position.Column,
-					issue.Severity)
-			}
-			os.Exit(1)
-		}
-	} else {
-		result, err := client.ValidateCode(string(content), ruleIDs, "go")
-		if err != nil {
-			log.Fatalf("Validation failed: %v", err)
-		}
-
-		fmt.Printf("Raw validation result: %+v\n", result)
-
-		if result.Valid {
-			fmt.Println("Validation passed!")
-		} else {
-			fmt.Println("Validation failed:")
-			for _, issue := range result.Issues {
-				fmt.Printf("- [%s] %s (Severity: %s)\n", issue.RuleID, issue.Description, issue.Severity)
-			}
-			os.Exit(1)
-		}
+	if len(problems) == 0 {
+		fmt.Printf("All rules in %s are valid\n", rulesDir)
+		return
 	}
-}
-
-func auditRules(cfg cliConfig) {
-	fmt.Println("Auditing rules for enforcement drift...")
-	fmt.Printf("Checking rules in %s against mechanisms in %s\n", cfg.rulesDir, cfg.mechanismsDir)
-	fmt.Printf("Results will be written to %s\n", cfg.outputFile)
 	
-	// In the MVP, we just report that we're auditing without actual implementation
-	// This would be expanded in a full implementation
-	fmt.Println("MVP: Drift analysis not yet implemented")
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	fmt.Printf("%d problem(s) found\n", len(problems))
+	os.Exit(1)
 }
 
 func testConnection(cfg cliConfig) {
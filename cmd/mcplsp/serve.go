@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/lsp"
+)
+
+// serve runs mcplsp as a language server on stdin and stdout.
+func serve(cfg cliConfig) {
+	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
+	lspMode := serveCmd.Bool("lsp", false, "Speak the Language Server Protocol on stdin/stdout")
+	templatesDir := serveCmd.String("templates", "./server/mcpserver/templates", "Path to the code templates linked from hovers")
+	proxy := serveCmd.Bool("proxy", false, "Wrap gopls and merge governance results into its responses")
+	gopls := serveCmd.String("gopls", "gopls", "Command that starts the wrapped language server in proxy mode")
+	serveCmd.Parse(flag.Args()[1:])
+
+	if !*lspMode {
+		log.Fatal("Usage: mcplsp [flags] serve --lsp [--proxy [--gopls command]]")
+	}
+
+	// stdout carries the protocol; logs go to stderr
+	log.SetOutput(os.Stderr)
+
+	config := lsp.Config{
+		Registry:     loadRegistry(cfg),
+		TypeCheck:    cfg.typeCheck,
+		TemplatesDir: *templatesDir,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *proxy {
+		command := strings.Fields(*gopls)
+		if len(command) == 0 {
+			log.Fatal("Usage: mcplsp [flags] serve --lsp --proxy --gopls command")
+		}
+		server := lsp.NewProxy(lsp.ProxyConfig{Config: config, Command: command})
+		if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			log.Fatalf("Language server proxy error: %v", err)
+		}
+		return
+	}
+
+	server := lsp.NewServer(config)
+
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+		log.Fatalf("Language server error: %v", err)
+	}
+}
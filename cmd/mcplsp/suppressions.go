@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+// listSuppressions runs every rule over the given files and reports the
+// //mcplsp:ignore directives that lack a rule or a reason, name an unknown
+// rule or silence nothing. It exits with status 1 when any are found.
+func listSuppressions(cfg cliConfig) {
+	args := flag.Args()[1:]
+	if len(args) == 0 {
+		log.Fatal("Missing files to check")
+	}
+
+	files, err := expandTargets(args)
+	if err != nil {
+		log.Fatalf("Failed to resolve files: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatal("No Go files to check")
+	}
+
+	registry := loadRegistry(cfg)
+	ruleIDs := allRuleIDs(registry)
+
+	groups := make(map[string][]string)
+	var dirs []string
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if _, ok := groups[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], file)
+	}
+
+	engine, err := analyzer.NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: cfg.typeCheck}, registry)
+	if err != nil {
+		log.Fatalf("Invalid rules: %v", err)
+	}
+
+	var suppressions []*ast.Suppression
+	for _, dir := range dirs {
+		result, err := engine.AnalyzeFiles(groups[dir], ruleIDs)
+		if err != nil {
+			log.Fatalf("Failed to analyze %s: %v", dir, err)
+		}
+		suppressions = append(suppressions, result.Suppressions...)
+	}
+	sort.SliceStable(suppressions, func(i, j int) bool {
+		a, b := suppressions[i].Position, suppressions[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+
+	problems := 0
+	for _, s := range suppressions {
+		status := suppressionStatus(engine, ruleIDs, s)
+		if status != "used" {
+			problems++
+		}
+		fmt.Printf("%s:%d: %s %s [%s]: %s\n", s.Position.Filename, s.Position.Line, s.Scope, strings.Join(s.RuleIDs, ","), s.Reason, status)
+	}
+
+	fmt.Printf("%d suppression(s), %d problem(s)\n", len(suppressions), problems)
+	if problems > 0 {
+		os.Exit(1)
+	}
+}
+
+// suppressionStatus describes whether a directive is well-formed and used.
+func suppressionStatus(engine *analyzer.AnalyzerEngine, ruleIDs []string, s *ast.Suppression) string {
+	if len(s.RuleIDs) == 0 {
+		return "missing rule"
+	}
+	if s.Reason == "" {
+		return "missing reason"
+	}
+	for _, name := range s.RuleIDs {
+		known := false
+		for _, id := range ruleIDs {
+			if engine.Names(name, id) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "unknown rule " + name
+		}
+	}
+	if !s.Used {
+		return "unused"
+	}
+	return "used"
+}
+
+// allRuleIDs returns the ids of the declared rules and the registered
+// analyses.
+func allRuleIDs(registry *rules.RuleRegistry) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	if registry != nil {
+		for _, id := range registry.IDs() {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, rule := range analyzer.Rules() {
+		if !seen[rule.ID()] {
+			seen[rule.ID()] = true
+			ids = append(ids, rule.ID())
+		}
+	}
+	return ids
+}
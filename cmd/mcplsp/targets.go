@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	analyzerast "github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+// isTargetArg reports whether a validate argument names files to validate
+// rather than a list of rule ids.
+func isTargetArg(arg string) bool {
+	if _, err := os.Stat(arg); err == nil {
+		return true
+	}
+	return strings.HasSuffix(arg, "...") || strings.ContainsAny(arg, "*?[") || strings.HasSuffix(arg, ".go")
+}
+
+// expandTargets resolves validate arguments into a sorted list of Go files:
+//
+//   - a file is validated as given
+//   - a directory selects the package in it
+//   - dir/... selects every package below dir, skipping vendor, testdata,
+//     hidden directories and nested modules
+//   - a glob selects matching .go files; ** matches any number of directories
+//
+// Test files and generated files are skipped unless named explicitly.
+func expandTargets(args []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, arg := range args {
+		if root, ok := strings.CutSuffix(arg, "..."); ok {
+			root = strings.TrimSuffix(root, "/")
+			if root == "" {
+				root = "."
+			}
+			dirs, err := analyzerast.PackageDirs(root)
+			if err != nil {
+				return nil, err
+			}
+			for _, dir := range dirs {
+				if err := addPackageFiles(dir, add); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if info, err := os.Stat(arg); err == nil {
+			if info.IsDir() {
+				if err := addPackageFiles(arg, add); err != nil {
+					return nil, err
+				}
+			} else {
+				add(arg)
+			}
+			continue
+		}
+
+		if !strings.ContainsAny(arg, "*?[") {
+			return nil, fmt.Errorf("no such file or directory: %s", arg)
+		}
+
+		matches, err := globFiles(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !strings.HasSuffix(match, "_test.go") && !isGenerated(match) {
+				add(match)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// addPackageFiles adds the non-test, non-generated Go files of the package in
+// dir that match the current build context.
+func addPackageFiles(dir string, add func(string)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if !isGenerated(path) {
+			add(path)
+		}
+	}
+
+	return nil
+}
+
+// isGenerated reports whether the file carries a "Code generated ... DO NOT
+// EDIT." header.
+func isGenerated(path string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false
+	}
+	return ast.IsGenerated(file)
+}
+
+// globFiles returns the .go files matching pattern. Besides the usual
+// filepath.Match syntax, a ** path element matches zero or more directories.
+// Vendor directories are never descended into.
+func globFiles(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	parts := strings.Split(pattern, "/")
+
+	// Walk from the longest prefix without wildcards.
+	var static []string
+	for _, part := range parts {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+		static = append(static, part)
+	}
+	root := strings.Join(static, "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		ok, err := matchSegments(parts, strings.Split(filepath.ToSlash(path), "/"))
+		if err != nil {
+			return err
+		}
+		if ok {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %s: %w", pattern, err)
+	}
+
+	return matches, nil
+}
+
+func matchSegments(pattern, path []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if ok, err := matchSegments(pattern[1:], path[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(path) == 0 {
+			return false, nil
+		}
+		ok, err := filepath.Match(pattern[0], path[0])
+		if err != nil || !ok {
+			return false, err
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+
+	return len(path) == 0, nil
+}
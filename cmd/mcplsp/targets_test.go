@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"pkg/*.go", "pkg/a.go", true},
+		{"pkg/*.go", "pkg/sub/a.go", false},
+		{"pkg/**/*.go", "pkg/a.go", true},
+		{"pkg/**/*.go", "pkg/sub/deep/a.go", true},
+		{"**/a.go", "x/y/a.go", true},
+		{"**/a.go", "x/y/b.go", false},
+	}
+
+	for _, tt := range tests {
+		got, err := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"))
+		if err != nil {
+			t.Fatalf("matchSegments(%q, %q): %v", tt.pattern, tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExpandTargets(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.go":               "package p\n",
+		"a_test.go":          "package p\n",
+		"gen.go":             "// Code generated by tool. DO NOT EDIT.\n\npackage p\n",
+		"sub/b.go":           "package sub\n",
+		"vendor/v/v.go":      "package v\n",
+		"testdata/t.go":      "package t\n",
+		"nested/go.mod":      "module nested\n",
+		"nested/n.go":        "package nested\n",
+		"sub/deep/c.go":      "package deep\n",
+		"sub/deep/notes.txt": "not go\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rel := func(paths ...string) []string {
+		for i, p := range paths {
+			paths[i] = filepath.Join(root, p)
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"file", rel("a_test.go"), rel("a_test.go")},
+		{"package", rel("."), rel("a.go")},
+		{"recursive", []string{root + "/..."}, rel("a.go", "sub/b.go", "sub/deep/c.go")},
+		{"glob", []string{root + "/sub/**/*.go"}, rel("sub/b.go", "sub/deep/c.go")},
+		{"duplicates", []string{root, root + "/a.go"}, rel("a.go")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandTargets(tt.args)
+			if err != nil {
+				t.Fatalf("expandTargets: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandTargets(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := expandTargets([]string{filepath.Join(root, "missing.go")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
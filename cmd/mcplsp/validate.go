@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/baseline"
+	"github.com/yourorg/go-mcp-lsp/pkg/mcpclient"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func validate(cfg cliConfig) {
+	validateCmd := flag.NewFlagSet("validate", flag.ExitOnError)
+	baselineFile := validateCmd.String("baseline", "", "Report only findings not recorded in this baseline file")
+	diffFile := validateCmd.String("diff", "", "Report only findings on lines added by this unified diff")
+	since := validateCmd.String("since", "", "Report only findings on lines changed since this git revision")
+	validateCmd.Parse(flag.Args()[1:])
+
+	if *diffFile != "" && *since != "" {
+		log.Fatal("Use either -diff or -since, not both")
+	}
+
+	args := validateCmd.Args()
+	if len(args) == 0 {
+		log.Fatal("Missing files to validate")
+	}
+
+	registry := loadRegistry(cfg)
+	ruleIDs, args := ruleArgs(registry, args)
+
+	var known *baseline.Baseline
+	if *baselineFile != "" {
+		var err error
+		if known, err = baseline.Load(*baselineFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if _, err := report.New(cfg.format); err != nil {
+		log.Fatal(err)
+	}
+	// Progress messages would corrupt machine-readable output on stdout
+	progress := func(format string, args ...interface{}) {
+		if cfg.format == "text" || !report.ToStdout(cfg.outputFile) {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	files, err := expandTargets(args)
+	if err != nil {
+		log.Fatalf("Failed to resolve files: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatal("No Go files to validate")
+	}
+
+	progress("Validating %d file(s) against rules: %v\n", len(files), ruleIDs)
+
+	results := collectResults(cfg, registry, files, ruleIDs, progress)
+	// The baseline is applied to every finding before the changed-lines
+	// filter; otherwise entries for findings outside the diff look stale
+	if known != nil {
+		var stale []baseline.Entry
+		var err error
+		if results, stale, err = known.Filter(results, ruleIDs); err != nil {
+			log.Fatalf("Failed to apply baseline: %v", err)
+		}
+		// Stale entries are reported on stderr so they do not corrupt
+		// machine-readable output
+		for _, entry := range stale {
+			fmt.Fprintf(os.Stderr, "Stale baseline entry: %s [%s] %s (%d finding(s) no longer reported)\n", entry.File, entry.RuleID, entry.Description, entry.Count)
+		}
+		if len(stale) > 0 {
+			fmt.Fprintf(os.Stderr, "Baseline %s has %d stale entry(ies); run 'mcplsp baseline create' to refresh it\n", *baselineFile, len(stale))
+		}
+	}
+	if *diffFile != "" || *since != "" {
+		changes, root, err := loadChanges(*diffFile, *since)
+		if err != nil {
+			log.Fatal(err)
+		}
+		progress("Reporting findings on lines changed in %d file(s)\n", len(changes))
+		results = filterChanged(results, changes, root)
+	}
+
+	result := &report.Result{Files: results}
+	if registry != nil {
+		result.Rules = registry.Rules()
+	}
+	if err := report.Write(cfg.outputFile, cfg.format, result); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if !report.ToStdout(cfg.outputFile) {
+		fmt.Printf("Report written to %s\n", cfg.outputFile)
+	}
+
+	if !result.Passed() {
+		os.Exit(1)
+	}
+}
+
+// ruleArgs returns the rules to validate against and the remaining target
+// arguments. A trailing argument that names no files is the list of rules;
+// otherwise every declared rule is used.
+func ruleArgs(registry *rules.RuleRegistry, args []string) ([]string, []string) {
+	ruleIDs := []string{"error_handling", "api_design", "concurrent_map_access", "secure_coding", "org_coding_standards"}
+	if registry != nil {
+		ruleIDs = registry.IDs()
+	}
+	if last := args[len(args)-1]; len(args) > 1 && !isTargetArg(last) {
+		ruleIDs = strings.Split(last, ",")
+		args = args[:len(args)-1]
+	}
+	return ruleIDs, args
+}
+
+// collectResults validates the files locally with -deep or through the MCP
+// server otherwise.
+func collectResults(cfg cliConfig, registry *rules.RuleRegistry, files []string, ruleIDs []string, progress func(string, ...interface{})) []report.FileResult {
+	if cfg.deep {
+		// Use AST-based analyzer for deeper inspection
+		progress("Using deep AST-based code inspection...\n")
+		return validateDeep(cfg, registry, files, ruleIDs)
+	}
+	progress("Connecting to MCP server at %s\n", cfg.mcpEndpoint)
+	return validateRemote(cfg, files, ruleIDs)
+}
+
+// validateDeep analyzes the files locally. Files are grouped by directory so
+// that each package is analyzed with cross-file knowledge, and groups are
+// processed in parallel, each with its own engine.
+func validateDeep(cfg cliConfig, registry *rules.RuleRegistry, files []string, ruleIDs []string) []report.FileResult {
+	groups := make(map[string][]string)
+	var dirs []string
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if _, ok := groups[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], file)
+	}
+
+	config := ast.AnalyzerConfig{TypeCheck: cfg.typeCheck}
+
+	return runParallel(cfg.parallel, dirs, func(dir string) []report.FileResult {
+		engine, err := analyzer.NewAnalyzerEngineWithConfig(config, registry)
+		if err != nil {
+			log.Fatalf("Invalid rules: %v", err)
+		}
+
+		group := groups[dir]
+		result, err := engine.AnalyzeFiles(group, ruleIDs)
+		if err == nil {
+			return splitByFile(group, result.Issues)
+		}
+
+		// Analyze the files one by one so a broken file does not hide
+		// findings in the rest of its package.
+		var results []report.FileResult
+		for _, file := range group {
+			result, err := engine.AnalyzeFiles([]string{file}, ruleIDs)
+			if err != nil {
+				results = append(results, report.FileResult{Path: file, Err: err})
+				continue
+			}
+			results = append(results, report.FileResult{Path: file, Issues: result.Issues})
+		}
+		return results
+	})
+}
+
+// validateRemote sends each file to the MCP server, several at a time.
+func validateRemote(cfg cliConfig, files []string, ruleIDs []string) []report.FileResult {
+	client := mcpclient.New(cfg.mcpEndpoint)
+
+	return runParallel(cfg.parallel, files, func(file string) []report.FileResult {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return []report.FileResult{{Path: file, Err: err}}
+		}
+
+		result, err := client.ValidateCode(string(content), ruleIDs, "go")
+		if err != nil {
+			return []report.FileResult{{Path: file, Err: err}}
+		}
+
+		var issues []ast.Issue
+		for _, issue := range result.Issues {
+			position := token.Position{Filename: file}
+			if issue.Location != nil {
+				position.Line = issue.Location.Line
+				position.Column = issue.Location.Column
+			}
+			issues = append(issues, ast.Issue{
+				RuleID:      issue.RuleID,
+				Description: issue.Description,
+				Severity:    issue.Severity,
+				Position:    position,
+			})
+		}
+		return []report.FileResult{{Path: file, Issues: issues}}
+	})
+}
+
+// runParallel applies fn to every item using up to n workers and returns the
+// results sorted by path.
+func runParallel(n int, items []string, fn func(string) []report.FileResult) []report.FileResult {
+	if n < 1 {
+		n = 1
+	}
+
+	work := make(chan string)
+	var (
+		mu      sync.Mutex
+		results []report.FileResult
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				r := fn(item)
+				mu.Lock()
+				results = append(results, r...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, item := range items {
+		work <- item
+	}
+	close(work)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+func splitByFile(files []string, issues []ast.Issue) []report.FileResult {
+	byFile := make(map[string][]ast.Issue)
+	for _, issue := range issues {
+		byFile[issue.Position.Filename] = append(byFile[issue.Position.Filename], issue)
+	}
+
+	results := make([]report.FileResult, len(files))
+	for i, file := range files {
+		results[i] = report.FileResult{Path: file, Issues: byFile[file]}
+	}
+	return results
+}
@@ -1,16 +1,25 @@
 package analyzer
 
 import (
+	"fmt"
+	goast "go/ast"
+
 	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
 )
 
 type AnalysisResult struct {
 	Valid  bool
 	Issues []ast.Issue
+	// Suppressions are the //mcplsp:ignore directives in the analyzed
+	// files, marked with whether they silenced a finding.
+	Suppressions []*ast.Suppression
 }
 
 type AnalyzerEngine struct {
 	analyzer *ast.Analyzer
+	registry *rules.RuleRegistry
+	checks   map[string]*rules.CompiledRule
 }
 
 func NewAnalyzerEngine() *AnalyzerEngine {
@@ -18,9 +27,64 @@ func NewAnalyzerEngine() *AnalyzerEngine {
 		IncludeTests: false,
 	}
 	
-	return &AnalyzerEngine{
+	engine, _ := NewAnalyzerEngineWithConfig(config, nil)
+	return engine
+}
+
+// NewAnalyzerEngineWithRegistry creates an engine that resolves rule ids,
+// aliases and categories through the registry and evaluates the declarative
+// checks of the rules without a registered analysis.
+func NewAnalyzerEngineWithRegistry(registry *rules.RuleRegistry) (*AnalyzerEngine, error) {
+	return NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{}, registry)
+}
+
+// NewAnalyzerEngineWithConfig creates an engine with an explicit analyzer
+// configuration, for example to enable type-checked analysis. The registry
+// may be nil.
+func NewAnalyzerEngineWithConfig(config ast.AnalyzerConfig, registry *rules.RuleRegistry) (*AnalyzerEngine, error) {
+	engine := &AnalyzerEngine{
 		analyzer: ast.NewAnalyzer(config),
+		registry: registry,
+		checks:   make(map[string]*rules.CompiledRule),
 	}
+	
+	if registry != nil {
+		for _, rule := range registry.Rules() {
+			if err := engine.AddRuleChecks(rule); err != nil {
+				return nil, err
+			}
+		}
+	}
+	
+	return engine, nil
+}
+
+// Fork returns an engine for another analysis that shares the registry,
+// the compiled rule checks and the analyzer's importer but none of the
+// files parsed so far. See ast.Analyzer.Fork.
+func (e *AnalyzerEngine) Fork() *AnalyzerEngine {
+	checks := make(map[string]*rules.CompiledRule, len(e.checks))
+	for id, compiled := range e.checks {
+		checks[id] = compiled
+	}
+	return &AnalyzerEngine{
+		analyzer: e.analyzer.Fork(),
+		registry: e.registry,
+		checks:   checks,
+	}
+}
+
+// AddRuleChecks compiles the declarative checks of a YAML rule so that
+// Analyze evaluates them whenever the rule's ID is requested and no analysis
+// is registered for it.
+func (e *AnalyzerEngine) AddRuleChecks(rule *rules.Rule) error {
+	compiled, err := rules.CompileRule(rule)
+	if err != nil {
+		return err
+	}
+
+	e.checks[rule.ID] = compiled
+	return nil
 }
 
 func (e *AnalyzerEngine) Analyze(filepath string, content []byte, ruleIDs []string) (*AnalysisResult, error) {
@@ -29,29 +93,219 @@ func (e *AnalyzerEngine) Analyze(filepath string, content []byte, ruleIDs []stri
 		return nil, err
 	}
 	
+	ruleIDs, err = e.ResolveRuleIDs(ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	pass := &Pass{
+		Analyzer: e.analyzer,
+		Filename: filepath,
+		File:     file,
+		Src:      content,
+		Files:    []*goast.File{file},
+	}
+	
+	allIssues, suppressions := e.runRules(pass, ruleIDs)
+	
+	return &AnalysisResult{
+		Valid:        len(allIssues) == 0,
+		Issues:       allIssues,
+		Suppressions: suppressions,
+	}, nil
+}
+
+// AnalyzePackage analyzes every file of the package in dir together, so that
+// rules see declarations from all of the package's files. Build constraints
+// are honored and test files are included only when the engine's
+// AnalyzerConfig.IncludeTests is set.
+func (e *AnalyzerEngine) AnalyzePackage(dir string, ruleIDs []string) (*AnalysisResult, error) {
+	ruleIDs, err := e.ResolveRuleIDs(ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	pkgs, err := e.analyzer.ParsePackages(dir)
+	if err != nil {
+		return nil, err
+	}
+	
+	return e.analyzePackages(pkgs, ruleIDs), nil
+}
+
+// AnalyzeFiles analyzes the given files, treating files that share a
+// directory and package clause as one package.
+func (e *AnalyzerEngine) AnalyzeFiles(paths []string, ruleIDs []string) (*AnalysisResult, error) {
+	ruleIDs, err := e.ResolveRuleIDs(ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	pkgs, err := e.analyzer.ParseFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	
+	return e.analyzePackages(pkgs, ruleIDs), nil
+}
+
+func (e *AnalyzerEngine) analyzePackages(pkgs []*ast.Package, ruleIDs []string) *AnalysisResult {
+	var allIssues []ast.Issue
+	var allSuppressions []*ast.Suppression
+	for _, pkg := range pkgs {
+		files := pkg.ASTFiles()
+		for _, f := range pkg.Files {
+			pass := &Pass{
+				Analyzer: e.analyzer,
+				Filename: f.Path,
+				File:     f.AST,
+				Src:      f.Src,
+				Files:    files,
+			}
+			issues, suppressions := e.runRules(pass, ruleIDs)
+			allIssues = append(allIssues, issues...)
+			allSuppressions = append(allSuppressions, suppressions...)
+		}
+	}
+	
+	return &AnalysisResult{
+		Valid:        len(allIssues) == 0,
+		Issues:       allIssues,
+		Suppressions: allSuppressions,
+	}
+}
+
+// AnalyzeModule analyzes every package under root. Vendored code, testdata,
+// hidden directories and nested modules are skipped.
+func (e *AnalyzerEngine) AnalyzeModule(root string, ruleIDs []string) (*AnalysisResult, error) {
+	dirs, err := ast.PackageDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	
+	var allIssues []ast.Issue
+	var allSuppressions []*ast.Suppression
+	for _, dir := range dirs {
+		result, err := e.AnalyzePackage(dir, ruleIDs)
+		if err != nil {
+			return nil, err
+		}
+		allIssues = append(allIssues, result.Issues...)
+		allSuppressions = append(allSuppressions, result.Suppressions...)
+	}
+	
+	return &AnalysisResult{
+		Valid:        len(allIssues) == 0,
+		Issues:       allIssues,
+		Suppressions: allSuppressions,
+	}, nil
+}
+
+// runRules runs the registered analysis of every rule, or its declarative
+// checks when it has none, against the pass's file, dropping the findings silenced by the file's
+// suppression directives.
+func (e *AnalyzerEngine) runRules(pass *Pass, ruleIDs []string) ([]ast.Issue, []*ast.Suppression) {
 	var allIssues []ast.Issue
 	
 	for _, ruleID := range ruleIDs {
 		var issues []ast.Issue
 		
-		switch ruleID {
-		case "error_handling":
-			issues = e.analyzer.AnalyzeErrorHandling(file)
-		case "api_design":
-			issues = e.analyzer.AnalyzeAPIDesign(file)
-		case "concurrent_map_access", "synchronization":
-			issues = e.analyzer.AnalyzeConcurrencySafety(file)
-		case "secure_coding":
-			issues = e.analyzer.AnalyzeSecurityIssues(file)
-		case "org_coding_standards", "coding_standards":
-			issues = e.analyzer.AnalyzeOrganizationStandards(file)
+		pass.Rule = nil
+		if e.registry != nil {
+			pass.Rule, _ = e.registry.Lookup(ruleID)
+		}
+		// A registered analysis supersedes the rule's pattern checks, which
+		// would report the same problems less precisely
+		if rule, ok := Lookup(ruleID); ok {
+			issues = rule.Check(pass)
+		} else if compiled, ok := e.checks[ruleID]; ok {
+			issues = compiled.Execute(pass.Filename, pass.Src)
 		}
 		
 		allIssues = append(allIssues, issues...)
 	}
 	
-	return &AnalysisResult{
-		Valid:  len(allIssues) == 0,
-		Issues: allIssues,
-	}, nil
+	suppressions := pass.Analyzer.Suppressions(pass.File)
+	if len(suppressions) == 0 {
+		return allIssues, nil
+	}
+	
+	var kept []ast.Issue
+	for _, issue := range allIssues {
+		if !e.suppress(suppressions, issue) {
+			kept = append(kept, issue)
+		}
+	}
+	
+	return kept, suppressions
+}
+
+// suppress reports whether a valid directive silences the issue, marking
+// the directives that do as used. A directive may name a rule by id, alias
+// or category.
+func (e *AnalyzerEngine) suppress(suppressions []*ast.Suppression, issue ast.Issue) bool {
+	silenced := false
+	for _, s := range suppressions {
+		if !s.Valid() || !s.Covers(issue.Position.Filename, issue.Position.Line) {
+			continue
+		}
+		for _, name := range s.RuleIDs {
+			if e.Names(name, issue.RuleID) {
+				s.Used = true
+				silenced = true
+			}
+		}
+	}
+	return silenced
+}
+
+// Names reports whether name refers to the rule, by its id or through one
+// of the registry's aliases and categories.
+func (e *AnalyzerEngine) Names(name, ruleID string) bool {
+	if name == ruleID {
+		return true
+	}
+	if e.registry == nil {
+		return false
+	}
+	for _, rule := range e.registry.Resolve(name) {
+		if rule.ID == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveRuleIDs maps the requested ids, aliases and categories to declared
+// rule ids. Registered analyses without a YAML rule resolve to themselves, and
+// without a registry the ids are used as given.
+func (e *AnalyzerEngine) ResolveRuleIDs(names []string) ([]string, error) {
+	if e.registry == nil {
+		return names, nil
+	}
+	
+	var ids []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		rules := e.registry.Resolve(name)
+		if len(rules) == 0 {
+			if _, ok := Lookup(name); !ok {
+				return nil, fmt.Errorf("unknown rule: %s", name)
+			}
+			if !seen[name] {
+				seen[name] = true
+				ids = append(ids, name)
+			}
+			continue
+		}
+		
+		for _, rule := range rules {
+			if !seen[rule.ID] {
+				seen[rule.ID] = true
+				ids = append(ids, rule.ID)
+			}
+		}
+	}
+	
+	return ids, nil
 }
@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzePackageAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"types.go": `package worker
+
+type Cache struct {
+	entries map[string]string
+}
+`,
+		"worker.go": `package worker
+
+func (c *Cache) Refresh() {
+	go func() {
+		c.entries["key"] = "value"
+	}()
+}
+`,
+		"worker_test.go": `package worker
+
+func helper_func() {}
+`,
+		"ignored_linux_only.go": `//go:build ignore
+
+package worker
+
+func do_ignored() {}
+`,
+	})
+
+	engine := NewAnalyzerEngine()
+	result, err := engine.AnalyzePackage(dir, []string{"concurrent_map_access", "org_coding_standards"})
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	if len(result.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %+v", len(result.Issues), result.Issues)
+	}
+
+	issue := result.Issues[0]
+	if issue.RuleID != "concurrent_map_access" || filepath.Base(issue.Position.Filename) != "worker.go" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+}
+
+func TestGoroutineLifecycleGoVersionFromDisk(t *testing.T) {
+	src := `package worker
+
+func run(items []string, results chan string) {
+	for _, item := range items {
+		go func() {
+			results <- item
+		}()
+	}
+}
+`
+	// The module above the working directory predates per-iteration loop
+	// variables; only the file on disk belongs to it
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":           "module example.com/worker\n\ngo 1.21\n",
+		"worker/worker.go": src,
+	})
+	t.Chdir(dir)
+
+	engine := NewAnalyzerEngine()
+	for _, tt := range []struct {
+		filename string
+		want     int
+	}{
+		{filename: filepath.Join(dir, "worker", "worker.go"), want: 1},
+		{filename: "buffer.go", want: 0},
+	} {
+		result, err := engine.Analyze(tt.filename, []byte(src), []string{"goroutine_lifecycle"})
+		if err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+		if len(result.Issues) != tt.want {
+			t.Errorf("%s: expected %d issue(s), got %+v", tt.filename, tt.want, result.Issues)
+		}
+	}
+}
+
+func TestAnalyzeModuleSkipsVendorAndTestdata(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                 "module example.com/m\n",
+		"a/a.go":                 "package a\n\nfunc do_a() {}\n",
+		"vendor/v/v.go":          "package v\n\nfunc do_v() {}\n",
+		"testdata/t.go":          "package t\n\nfunc do_t() {}\n",
+		"nested/go.mod":          "module example.com/nested\n",
+		"nested/n.go":            "package nested\n\nfunc do_n() {}\n",
+		"b/internal/b.go":        "package internal\n\nfunc do_b() {}\n",
+		"b/internal/b_linux.go":  "package internal\n",
+		"b/internal/b_plan9.go":  "package internal\n\nfunc do_plan9() {}\n",
+		"b/internal/README.md":   "not go",
+		".hidden/h.go":           "package h\n\nfunc do_h() {}\n",
+		"cmd/tool/main.go":       "package main\n\nfunc main() {}\n",
+		"cmd/tool/main_extra.go": "package main\n",
+	})
+
+	engine := NewAnalyzerEngine()
+	result, err := engine.AnalyzeModule(dir, []string{"org_coding_standards"})
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	var files []string
+	for _, issue := range result.Issues {
+		rel, _ := filepath.Rel(dir, issue.Position.Filename)
+		files = append(files, filepath.ToSlash(rel))
+	}
+
+	expected := []string{"a/a.go", "b/internal/b.go"}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected issues in %v, got %v", expected, files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("Expected issue in %s, got %s", expected[i], files[i])
+		}
+	}
+}
+
+func TestAnalyzeHonorsSuppressions(t *testing.T) {
+	src := `package store
+
+import "errors"
+
+var ErrNotFound = errors.New("not found") //mcplsp:ignore org_coding_standards sentinel error
+
+var ErrClosed = errors.New("closed") //mcplsp:ignore org_coding_standards
+
+//mcplsp:ignore org_coding_standards generated name
+func do_generated() {}
+
+//mcplsp:ignore org_coding_standards nothing to silence here
+func Valid() {}
+`
+	engine := NewAnalyzerEngine()
+	result, err := engine.Analyze("store.go", []byte(src), []string{"org_coding_standards"})
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	// The reason-less directive does not silence ErrClosed
+	if len(result.Issues) != 1 || result.Issues[0].Position.Line != 7 {
+		t.Fatalf("Expected 1 issue on line 7, got %+v", result.Issues)
+	}
+
+	var used []bool
+	for _, s := range result.Suppressions {
+		used = append(used, s.Used)
+	}
+	expected := []bool{true, false, true, false}
+	if len(used) != len(expected) {
+		t.Fatalf("Expected %d suppressions, got %d", len(expected), len(used))
+	}
+	for i := range expected {
+		if used[i] != expected[i] {
+			t.Errorf("Suppression %d: expected used %v, got %v", i, expected[i], used[i])
+		}
+	}
+}
+
+func TestShippedRulesKeepCompliantCodeClean(t *testing.T) {
+	registry, err := rules.NewRuleRegistry("../../server/mcpserver/rules")
+	if err != nil {
+		t.Fatalf("NewRuleRegistry: %v", err)
+	}
+
+	// A rule's pattern checks only run when it has no registered analysis,
+	// so neither reports what the other already does
+	paths := []string{
+		"../../testdata/error_handling/proper_check.go",
+		"../../testdata/api_design/proper_context.go",
+		"../../testdata/security/secure_practices.go",
+		"../../testdata/sample_good.go",
+	}
+	for _, typeCheck := range []bool{false, true} {
+		engine, err := NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: typeCheck}, registry)
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+		for _, path := range paths {
+			result, err := engine.Fork().AnalyzeFiles([]string{path}, registry.IDs())
+			if err != nil {
+				t.Fatalf("Analysis of %s failed: %v", path, err)
+			}
+			for _, issue := range result.Issues {
+				t.Errorf("TypeCheck %v: unexpected issue %s at %s", typeCheck, issue.Description, issue.Position)
+			}
+		}
+	}
+}
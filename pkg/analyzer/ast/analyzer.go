@@ -5,6 +5,8 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"sort"
 	"strings"
 )
 
@@ -13,21 +15,32 @@ type Issue struct {
 	Description string
 	Severity    string
 	Position    token.Position
+	// SuggestedFixes are the changes that would resolve the issue, if any
+	// can be derived from the code.
+	SuggestedFixes []SuggestedFix
 }
 
 type AnalyzerConfig struct {
 	IncludeTests bool
+	// TypeCheck runs go/types over parsed files so that analyses recognize
+	// errors, mutexes and contexts by type rather than by name.
+	TypeCheck bool
 }
 
 type Analyzer struct {
-	config AnalyzerConfig
-	fset   *token.FileSet
+	config   AnalyzerConfig
+	fset     *token.FileSet
+	info     *types.Info
+	importer types.Importer
+	// sources holds the text of parsed files by name, for building fixes
+	sources map[string][]byte
 }
 
 func NewAnalyzer(config AnalyzerConfig) *Analyzer {
 	return &Analyzer{
-		config: config,
-		fset:   token.NewFileSet(),
+		config:  config,
+		fset:    token.NewFileSet(),
+		sources: make(map[string][]byte),
 	}
 }
 
@@ -41,156 +54,119 @@ func (a *Analyzer) ParseFile(filepath string, src []byte) (*ast.File, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file: %w", err)
 	}
+	if src != nil {
+		a.sources[filepath] = src
+	}
+	
+	if a.config.TypeCheck {
+		a.TypeCheck(file)
+	}
 	
 	return file, nil
 }
 
 func (a *Analyzer) ParseString(filename, content string) (*ast.File, error) {
-	return parser.ParseFile(token.NewFileSet(), filename, content, parser.AllErrors)
+	file, err := parser.ParseFile(a.fset, filename, content, parser.ParseComments|parser.AllErrors)
+	if err != nil {
+		return nil, err
+	}
+	a.sources[filename] = []byte(content)
+	
+	if a.config.TypeCheck {
+		a.TypeCheck(file)
+	}
+	
+	return file, nil
 }
 
 func (a *Analyzer) GetPositionOf(node ast.Node) token.Position {
 	return a.fset.Position(node.Pos())
 }
 
+// AnalyzeErrorHandling reports errors that are discarded with a blank
+// identifier, and error assignments that reach a reassignment or the end of
+// the function on some path without the error being inspected. The latter
+// are found by following each assignment through the control-flow graph of
+// its function.
 func (a *Analyzer) AnalyzeErrorHandling(file *ast.File) []Issue {
 	var issues []Issue
 	
 	ast.Inspect(file, func(n ast.Node) bool {
-		if node, ok := n.(*ast.AssignStmt); ok {
-			// Only check statements that might be assigning an error
-			hasErrorVar := false
-			for _, lhs := range node.Lhs {
-				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "err" {
-					hasErrorVar = true
-					break
-				}
-			}
-			
-			if hasErrorVar {
-				// Find the nearest containing block statement
-				var containingBlock *ast.BlockStmt
-				parent := a.findParentNode(file, node)
-				for parent != nil {
-					if block, ok := parent.(*ast.BlockStmt); ok {
-						containingBlock = block
-						break
-					}
-					parent = a.findParentNode(file, parent)
-				}
-				
-				// If we found a containing block, check if it contains error handling
-				if containingBlock != nil {
-					if !a.blockContainsErrorCheck(containingBlock, node) {
-						issues = append(issues, Issue{
-							RuleID:      "error_handling",
-							Description: "Missing error check after error assignment",
-							Severity:    "warning",
-							Position:    a.GetPositionOf(node),
-						})
-					}
-				}
-			}
-			
-			if a.isErrorIgnored(node) {
+		var typ *ast.FuncType
+		var body *ast.BlockStmt
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			typ, body = node.Type, node.Body
+		case *ast.FuncLit:
+			typ, body = node.Type, node.Body
+		case *ast.AssignStmt:
+			// With type information discarded_errors reports errors
+			// assigned to _, honouring its allowlist
+			if !a.HasTypeInfo() && a.isErrorIgnored(node) {
 				issues = append(issues, Issue{
-					RuleID:      "error_handling",
-					Description: "Error is being ignored with underscore assignment",
-					Severity:    "error",
-					Position:    a.GetPositionOf(node),
+					RuleID:         "error_handling",
+					Description:    "Error is being ignored with underscore assignment",
+					Severity:       "error",
+					Position:       a.GetPositionOf(node),
+					SuggestedFixes: a.fixer(file).checkIgnoredError(node),
 				})
 			}
 		}
-		return true
-	})
-	
-	return issues
-}
-
-func (a *Analyzer) findParentNode(file *ast.File, target ast.Node) ast.Node {
-	var parent ast.Node
-	
-	ast.Inspect(file, func(n ast.Node) bool {
-		if n == nil {
-			return false
+		if body == nil {
+			return true
 		}
 		
-		// Check if any child nodes match our target
-		switch x := n.(type) {
-		case *ast.BlockStmt:
-			for _, stmt := range x.List {
-				if stmt == target {
-					parent = n
-					return false
-				}
+		reported := make(map[ast.Node]bool)
+		for _, def := range a.uncheckedErrors(n, typ, body) {
+			// Report a statement once even if it assigns several errors
+			if reported[def.node] {
+				continue
 			}
-		case *ast.IfStmt:
-			if x.Cond == target || x.Body == target || x.Else == target {
-				parent = n
-				return false
+			reported[def.node] = true
+			
+			description := "Missing error check after error assignment"
+			if def.overwritten {
+				description = fmt.Sprintf("Error assigned to %s is overwritten before being checked", def.name.Name)
 			}
-		case *ast.AssignStmt:
-			for _, expr := range x.Lhs {
-				if expr == target {
-					parent = n
-					return false
-				}
+			issue := Issue{
+				RuleID:      "error_handling",
+				Description: description,
+				Severity:    "warning",
+				Position:    a.GetPositionOf(def.node),
 			}
-			for _, expr := range x.Rhs {
-				if expr == target {
-					parent = n
-					return false
-				}
+			if assign, ok := def.node.(*ast.AssignStmt); ok {
+				issue.SuggestedFixes = a.fixer(file).checkError(assign)
 			}
+			issues = append(issues, issue)
 		}
-		
 		return true
 	})
 	
-	return parent
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Position.Offset < issues[j].Position.Offset
+	})
+	return issues
 }
 
-func (a *Analyzer) blockContainsErrorCheck(block *ast.BlockStmt, errAssign ast.Node) bool {
-	foundAssignment := false
-	
-	// Iterate through statements to find error checks that come after our assignment
-	for _, stmt := range block.List {
-		if !foundAssignment {
-			if stmt == errAssign {
-				foundAssignment = true
-			}
-			continue
-		}
-		
-		// Check if this statement is an error check
-		if ifStmt, ok := stmt.(*ast.IfStmt); ok {
-			if binExpr, ok := ifStmt.Cond.(*ast.BinaryExpr); ok {
-				if binExpr.Op == token.NEQ {
-					if ident, ok := binExpr.X.(*ast.Ident); ok && ident.Name == "err" {
-						return true
-					}
-					if ident, ok := binExpr.Y.(*ast.Ident); ok && ident.Name == "err" {
-						return true
-					}
-				}
-			}
-		}
-	}
-	
-	return false
+func (a *Analyzer) AnalyzeAPIDesign(file *ast.File) []Issue {
+	return a.AnalyzePackageAPIDesign(file, []*ast.File{file})
 }
 
-func (a *Analyzer) AnalyzeAPIDesign(file *ast.File) []Issue {
+// AnalyzePackageAPIDesign reports the methods of file that do not take a
+// context.Context first. The other files of the package decide whether the
+// methods can be given a context by a suggested fix.
+func (a *Analyzer) AnalyzePackageAPIDesign(file *ast.File, pkgFiles []*ast.File) []Issue {
 	var issues []Issue
 	
 	ast.Inspect(file, func(n ast.Node) bool {
 		if funcDecl, ok := n.(*ast.FuncDecl); ok {
-			if a.isReceiverMethod(funcDecl) && !a.hasContextParameter(funcDecl) {
+			if a.isReceiverMethod(funcDecl) && !a.hasContextParameter(funcDecl) && !implementsStdInterface(funcDecl) {
 				issues = append(issues, Issue{
-					RuleID:      "api_design",
-					Description: "API method missing context.Context as first parameter",
-					Severity:    "warning",
-					Position:    a.GetPositionOf(funcDecl),
+					RuleID:         "api_design",
+					Description:    "API method missing context.Context as first parameter",
+					Severity:       "warning",
+					Position:       a.GetPositionOf(funcDecl),
+					SuggestedFixes: a.fixer(file).addContext(funcDecl, pkgFiles),
 				})
 			}
 		}
@@ -202,60 +178,77 @@ func (a *Analyzer) AnalyzeAPIDesign(file *ast.File) []Issue {
 }
 
 func (a *Analyzer) AnalyzeConcurrencySafety(file *ast.File) []Issue {
+	return a.AnalyzePackageConcurrencySafety(file, []*ast.File{file})
+}
+
+// AnalyzePackageConcurrencySafety reports unprotected map accesses in the
+// goroutines of file, using the declarations of every file in the package so
+// that a struct declared in one file is matched with its use in another.
+func (a *Analyzer) AnalyzePackageConcurrencySafety(file *ast.File, pkgFiles []*ast.File) []Issue {
 	var issues []Issue
 	
+	if len(pkgFiles) == 0 {
+		pkgFiles = []*ast.File{file}
+	}
+	
 	// Find maps and their associated mutexes
 	mapVars := a.findMapVariables(file)
+	mapFields := make(map[string]bool)
 	protectedMaps := make(map[string]bool)
 	
-	// Find mutex-protected maps
-	ast.Inspect(file, func(n ast.Node) bool {
-		if structType, ok := n.(*ast.StructType); ok {
-			mapFields := make(map[string]bool)
-			hasMutex := false
-			
-			// Check if struct has both a map and a mutex
-			for _, field := range structType.Fields.List {
-				if _, ok := field.Type.(*ast.MapType); ok {
-					// Capture map field names
-					for _, name := range field.Names {
-						mapFields[name.Name] = true
-					}
-				}
+	for _, pkgFile := range pkgFiles {
+		if pkgFile != file {
+			for name := range a.findPackageMapVariables(pkgFile) {
+				mapVars[name] = true
+			}
+		}
+		
+		// Find mutex-protected maps
+		ast.Inspect(pkgFile, func(n ast.Node) bool {
+			if structType, ok := n.(*ast.StructType); ok {
+				structMaps := make(map[string]bool)
+				hasMutex := false
 				
-				if fieldType, ok := field.Type.(*ast.SelectorExpr); ok {
-					if x, ok := fieldType.X.(*ast.Ident); ok && x.Name == "sync" {
-						if fieldType.Sel.Name == "Mutex" || fieldType.Sel.Name == "RWMutex" {
-							hasMutex = true
+				// Check if struct has both a map and a mutex
+				for _, field := range structType.Fields.List {
+					if _, ok := field.Type.(*ast.MapType); ok {
+						// Capture map field names
+						for _, name := range field.Names {
+							structMaps[name.Name] = true
+							mapFields[name.Name] = true
 						}
 					}
+					
+					if _, ok := field.Type.(*ast.MapType); !ok && a.IsMutex(field.Type) {
+						hasMutex = true
+					}
 				}
-			}
-			
-			// If struct has both map and mutex, consider the maps protected
-			if hasMutex {
-				for mapName := range mapFields {
-					protectedMaps[mapName] = true
+				
+				// If struct has both map and mutex, consider the maps protected
+				if hasMutex {
+					for mapName := range structMaps {
+						protectedMaps[mapName] = true
+					}
 				}
 			}
-		}
-		return true
-	})
+			return true
+		})
+	}
 	
 	// Check for concurrent map access outside of mutex lock/unlock
 	ast.Inspect(file, func(n ast.Node) bool {
 		if goStmt, ok := n.(*ast.GoStmt); ok {
 			// Track map accesses and mutex locks within goroutines
 			mapAccesses := make(map[string]ast.Node)
-			mutexLocked := make(map[string]bool)
+			mutexLocked := false
 			
 			ast.Inspect(goStmt.Call.Fun, func(innerNode ast.Node) bool {
 				// Track mutex lock operations
 				if callExpr, ok := innerNode.(*ast.CallExpr); ok {
 					if selExpr, ok := callExpr.Fun.(*ast.SelectorExpr); ok {
-						if ident, ok := selExpr.X.(*ast.Ident); ok {
-							if selExpr.Sel.Name == "Lock" || selExpr.Sel.Name == "RLock" {
-								mutexLocked[ident.Name] = true
+						if selExpr.Sel.Name == "Lock" || selExpr.Sel.Name == "RLock" {
+							if a.IsMutex(selExpr.X) {
+								mutexLocked = true
 							}
 						}
 					}
@@ -263,9 +256,14 @@ func (a *Analyzer) AnalyzeConcurrencySafety(file *ast.File) []Issue {
 				
 				// Track map access
 				if indexExpr, ok := innerNode.(*ast.IndexExpr); ok {
-					if ident, ok := indexExpr.X.(*ast.Ident); ok {
-						if mapVars[ident.Name] {
-							mapAccesses[ident.Name] = indexExpr
+					switch x := indexExpr.X.(type) {
+					case *ast.Ident:
+						if mapVars[x.Name] || a.IsMap(x) {
+							mapAccesses[x.Name] = indexExpr
+						}
+					case *ast.SelectorExpr:
+						if mapFields[x.Sel.Name] || a.IsMap(x) {
+							mapAccesses[x.Sel.Name] = indexExpr
 						}
 					}
 				}
@@ -275,14 +273,17 @@ func (a *Analyzer) AnalyzeConcurrencySafety(file *ast.File) []Issue {
 			
 			// Check if map accesses are protected
 			for mapName, accessNode := range mapAccesses {
-				if !mutexLocked["mu"] && !mutexLocked["mutex"] && !mutexLocked["rwmu"] && 
-				   !mutexLocked["rwmutex"] && !mutexLocked["lock"] && !protectedMaps[mapName] {
-					issues = append(issues, Issue{
+				if !mutexLocked && !protectedMaps[mapName] {
+					issue := Issue{
 						RuleID:      "concurrent_map_access",
 						Description: fmt.Sprintf("Map '%s' accessed in goroutine without mutex protection", mapName),
 						Severity:    "error",
 						Position:    a.GetPositionOf(accessNode),
-					})
+					}
+					if index, ok := accessNode.(*ast.IndexExpr); ok {
+						issue.SuggestedFixes = a.fixer(file).addMutex(index)
+					}
+					issues = append(issues, issue)
 				}
 			}
 		}
@@ -334,6 +335,13 @@ func (a *Analyzer) AnalyzeSecurityIssues(file *ast.File) []Issue {
 }
 
 func (a *Analyzer) AnalyzeOrganizationStandards(file *ast.File) []Issue {
+	return a.AnalyzePackageOrganizationStandards(file, []*ast.File{file})
+}
+
+// AnalyzePackageOrganizationStandards reports exported globals and
+// snake_case function names in file. The other files of the package decide
+// whether a function can be renamed by a suggested fix.
+func (a *Analyzer) AnalyzePackageOrganizationStandards(file *ast.File, pkgFiles []*ast.File) []Issue {
 	var issues []Issue
 	
 	// Check for global variables
@@ -363,10 +371,11 @@ func (a *Analyzer) AnalyzeOrganizationStandards(file *ast.File) []Issue {
 		if funcDecl, ok := n.(*ast.FuncDecl); ok {
 			if strings.Contains(funcDecl.Name.Name, "_") {
 				issues = append(issues, Issue{
-					RuleID:      "org_coding_standards",
-					Description: fmt.Sprintf("Function '%s' uses snake_case which violates naming conventions", funcDecl.Name.Name),
-					Severity:    "warning",
-					Position:    a.GetPositionOf(funcDecl.Name),
+					RuleID:         "org_coding_standards",
+					Description:    fmt.Sprintf("Function '%s' uses snake_case which violates naming conventions", funcDecl.Name.Name),
+					Severity:       "warning",
+					Position:       a.GetPositionOf(funcDecl.Name),
+					SuggestedFixes: a.fixer(file).renameSnakeCase(funcDecl, pkgFiles),
 				})
 			}
 		}
@@ -422,6 +431,20 @@ func (a *Analyzer) hasErrorCheck(node ast.Node) bool {
 }
 
 func (a *Analyzer) isErrorIgnored(node *ast.AssignStmt) bool {
+	// With type information, check the result actually assigned to each blank
+	if len(node.Rhs) == 1 {
+		if callExpr, ok := node.Rhs[0].(*ast.CallExpr); ok {
+			if results := a.ResultTypes(callExpr); len(results) == len(node.Lhs) {
+				for i, lhs := range node.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "_" && types.Identical(results[i], errorType) {
+						return true
+					}
+				}
+				return false
+			}
+		}
+	}
+	
 	for _, lhs := range node.Lhs {
 		if ident, ok := lhs.(*ast.Ident); ok {
 			if ident.Name == "_" {
@@ -458,6 +481,37 @@ func (a *Analyzer) isReceiverMethod(node *ast.FuncDecl) bool {
 	return node.Recv != nil && len(node.Recv.List) > 0
 }
 
+// stdInterfaceMethods maps the methods of standard library interfaces, such
+// as error, fmt.Stringer, io.Closer and sort.Interface, to their number of
+// parameters. Their signatures are fixed, so they cannot take a context.
+var stdInterfaceMethods = map[string]int{
+	"Error":         0,
+	"String":        0,
+	"GoString":      0,
+	"Unwrap":        0,
+	"Is":            1,
+	"As":            1,
+	"Format":        2,
+	"Len":           0,
+	"Less":          2,
+	"Swap":          2,
+	"Close":         0,
+	"Read":          1,
+	"Write":         1,
+	"MarshalJSON":   0,
+	"UnmarshalJSON": 1,
+	"MarshalText":   0,
+	"UnmarshalText": 1,
+	"ServeHTTP":     2,
+}
+
+// implementsStdInterface reports whether a method has the name and number
+// of parameters of a standard library interface method.
+func implementsStdInterface(node *ast.FuncDecl) bool {
+	n, ok := stdInterfaceMethods[node.Name.Name]
+	return ok && node.Type.Params.NumFields() == n
+}
+
 func (a *Analyzer) hasContextParameter(node *ast.FuncDecl) bool {
 	if node.Type.Params == nil || len(node.Type.Params.List) == 0 {
 		return false
@@ -466,12 +520,7 @@ func (a *Analyzer) hasContextParameter(node *ast.FuncDecl) bool {
 	firstParam := node.Type.Params.List[0]
 	
 	// Check if param type is context.Context
-	if sel, ok := firstParam.Type.(*ast.SelectorExpr); ok {
-		if ident, ok := sel.X.(*ast.Ident); ok {
-			return ident.Name == "context" && sel.Sel.Name == "Context"
-		}
-	}
-	return false
+	return a.IsContext(firstParam.Type)
 }
 
 func (a *Analyzer) findMapVariables(file *ast.File) map[string]bool {
@@ -512,6 +561,27 @@ func (a *Analyzer) findMapVariables(file *ast.File) map[string]bool {
 	return mapVars
 }
 
+// findPackageMapVariables returns the package-level map variables of a file.
+func (a *Analyzer) findPackageMapVariables(file *ast.File) map[string]bool {
+	mapVars := make(map[string]bool)
+	
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+			for _, spec := range genDecl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					if _, ok := valueSpec.Type.(*ast.MapType); ok {
+						for _, name := range valueSpec.Names {
+							mapVars[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	
+	return mapVars
+}
+
 func (a *Analyzer) hasMutexProtection(file *ast.File, mapVar string) bool {
 	// This is a simplification. In a real implementation, we would need to analyze
 	// the synchronization patterns more carefully.
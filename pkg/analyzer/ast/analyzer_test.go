@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"go/ast"
 	"testing"
 )
 
@@ -315,3 +316,138 @@ func (s *Service) GetConfig() map[string]string {
 		})
 	}
 }
+
+func TestTypeAwareAnalysis(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          string
+		analyze       func(*Analyzer, *ast.File) []Issue
+		expectedIssue bool
+	}{
+		{
+			name: "Aliased context import",
+			code: `package test
+import stdctx "context"
+
+type Service struct{}
+
+func (s *Service) DoSomething(ctx stdctx.Context, id string) error {
+	return nil
+}`,
+			analyze:       (*Analyzer).AnalyzeAPIDesign,
+			expectedIssue: false,
+		},
+		{
+			name: "Error variable not named err",
+			code: `package test
+import "os"
+
+func foo() {
+	file, openErr := os.Open("test.txt")
+	_ = file
+	_ = openErr
+}`,
+			analyze:       (*Analyzer).AnalyzeErrorHandling,
+			expectedIssue: true,
+		},
+		{
+			name: "Mutex not named mu",
+			code: `package test
+import "sync"
+
+func safeAccess() {
+	var guard sync.Mutex
+	m := make(map[string]string)
+
+	go func() {
+		guard.Lock()
+		m["key"] = "value"
+		guard.Unlock()
+	}()
+}`,
+			analyze:       (*Analyzer).AnalyzeConcurrencySafety,
+			expectedIssue: false,
+		},
+		{
+			name: "Ignored error from any callee",
+			code: `package test
+import "os"
+
+func foo() {
+	_ = os.Remove("test.txt")
+}`,
+			analyze: func(a *Analyzer, file *ast.File) []Issue {
+				return a.AnalyzeDiscardedErrors(file, DefaultDiscardAllowlist)
+			},
+			expectedIssue: true,
+		},
+		{
+			name: "Ignored error left to discarded_errors",
+			code: `package test
+import "os"
+
+func foo() {
+	_ = os.Remove("test.txt")
+}`,
+			analyze:       (*Analyzer).AnalyzeErrorHandling,
+			expectedIssue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{IncludeTests: true, TypeCheck: true})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			issues := tt.analyze(analyzer, file)
+			hasIssue := len(issues) > 0
+
+			if hasIssue != tt.expectedIssue {
+				t.Errorf("Expected issue: %v, got: %v", tt.expectedIssue, hasIssue)
+				for i, issue := range issues {
+					t.Logf("Issue %d: %s at line %d", i+1, issue.Description, issue.Position.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestForkKeepsFilesToItself(t *testing.T) {
+	const src = `package demo
+
+import "os"
+
+func load() ([]byte, error) {
+	return os.ReadFile("config.txt")
+}
+`
+	base := NewAnalyzer(AnalyzerConfig{TypeCheck: true})
+	analyze := func() {
+		t.Helper()
+		fork := base.Fork()
+		if fork.fset == base.fset {
+			t.Fatal("Expected the fork to have a file set of its own")
+		}
+		file, err := fork.ParseFile("demo.go", []byte(src))
+		if err != nil {
+			t.Fatalf("Failed to parse code: %v", err)
+		}
+		if errs := fork.TypeCheck(file); len(errs) != 0 {
+			t.Fatalf("Type-check failed: %v", errs)
+		}
+	}
+
+	// The first analysis imports os through the shared importer; later ones
+	// add nothing to the base file set
+	analyze()
+	size := base.fset.Base()
+	for i := 0; i < 3; i++ {
+		analyze()
+	}
+	if got := base.fset.Base(); got != size {
+		t.Errorf("Base file set grew from %d to %d", size, got)
+	}
+}
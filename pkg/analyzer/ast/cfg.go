@@ -0,0 +1,399 @@
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cfgBlock is a basic block of a function's control-flow graph: nodes that
+// execute in order, followed by a jump to one of succs. Nodes are simple
+// statements and the expressions control statements evaluate, such as an if
+// condition or a case value; a range statement stands for the assignment of
+// its key and value at the start of each iteration.
+type cfgBlock struct {
+	nodes []ast.Node
+	succs []*cfgBlock
+}
+
+// funcCFG is the control-flow graph of a function body. Return statements
+// and falling off the end of the body lead to exit, which has no nodes.
+// Blocks ending in a call that does not return, such as panic, have no
+// successors.
+type funcCFG struct {
+	blocks []*cfgBlock
+	exit   *cfgBlock
+}
+
+// cfgLabel holds the blocks a label refers to: the labeled statement for
+// goto, and for labeled loops, switches and selects the targets of break and
+// continue.
+type cfgLabel struct {
+	block          *cfgBlock
+	breakTarget    *cfgBlock
+	continueTarget *cfgBlock
+}
+
+type cfgBuilder struct {
+	cfg *funcCFG
+	// current is the block being filled, or nil after a jump
+	current        *cfgBlock
+	breakTarget    *cfgBlock
+	continueTarget *cfgBlock
+	// fallTarget is the body of the next case clause, for fallthrough
+	fallTarget *cfgBlock
+	labels     map[string]*cfgLabel
+	// label is the label of the statement being built, if any
+	label    *cfgLabel
+	noReturn func(*ast.CallExpr) bool
+}
+
+// buildCFG builds the control-flow graph of a function body. noReturn
+// reports calls after which control does not continue.
+func buildCFG(body *ast.BlockStmt, noReturn func(*ast.CallExpr) bool) *funcCFG {
+	b := &cfgBuilder{
+		cfg:      &funcCFG{},
+		labels:   make(map[string]*cfgLabel),
+		noReturn: noReturn,
+	}
+	b.cfg.exit = b.newBlock()
+	b.current = b.newBlock()
+	b.stmtList(body.List)
+	b.jump(b.cfg.exit)
+	return b.cfg
+}
+
+func (b *cfgBuilder) newBlock() *cfgBlock {
+	block := &cfgBlock{}
+	b.cfg.blocks = append(b.cfg.blocks, block)
+	return block
+}
+
+// add appends a node to the current block. Code following a jump starts a
+// new block without predecessors.
+func (b *cfgBuilder) add(n ast.Node) {
+	if b.current == nil {
+		b.current = b.newBlock()
+	}
+	b.current.nodes = append(b.current.nodes, n)
+}
+
+// jump ends the current block with an edge to target.
+func (b *cfgBuilder) jump(target *cfgBlock) {
+	if b.current != nil {
+		b.current.succs = append(b.current.succs, target)
+	}
+	b.current = nil
+}
+
+// branch ends the current block with edges to each of targets.
+func (b *cfgBuilder) branch(targets ...*cfgBlock) {
+	if b.current == nil {
+		b.current = b.newBlock()
+	}
+	b.current.succs = append(b.current.succs, targets...)
+	b.current = nil
+}
+
+func (b *cfgBuilder) labelFor(name string) *cfgLabel {
+	l, ok := b.labels[name]
+	if !ok {
+		l = &cfgLabel{block: b.newBlock()}
+		b.labels[name] = l
+	}
+	return l
+}
+
+func (b *cfgBuilder) stmtList(list []ast.Stmt) {
+	for _, s := range list {
+		b.stmt(s)
+	}
+}
+
+func (b *cfgBuilder) stmt(s ast.Stmt) {
+	// The label only applies to the statement it is attached to
+	label := b.label
+	b.label = nil
+
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		b.stmtList(s.List)
+
+	case *ast.LabeledStmt:
+		l := b.labelFor(s.Label.Name)
+		b.jump(l.block)
+		b.current = l.block
+		b.label = l
+		b.stmt(s.Stmt)
+
+	case *ast.IfStmt:
+		if s.Init != nil {
+			b.stmt(s.Init)
+		}
+		b.add(s.Cond)
+		then, done := b.newBlock(), b.newBlock()
+		otherwise := done
+		if s.Else != nil {
+			otherwise = b.newBlock()
+		}
+		b.branch(then, otherwise)
+
+		b.current = then
+		b.stmt(s.Body)
+		b.jump(done)
+		if s.Else != nil {
+			b.current = otherwise
+			b.stmt(s.Else)
+			b.jump(done)
+		}
+		b.current = done
+
+	case *ast.ForStmt:
+		if s.Init != nil {
+			b.stmt(s.Init)
+		}
+		loop, body, post, done := b.newBlock(), b.newBlock(), b.newBlock(), b.newBlock()
+		b.jump(loop)
+		b.current = loop
+		if s.Cond != nil {
+			b.add(s.Cond)
+			b.branch(body, done)
+		} else {
+			b.branch(body)
+		}
+
+		b.current = body
+		b.loopBody(s.Body, label, done, post)
+		b.jump(post)
+		b.current = post
+		if s.Post != nil {
+			b.stmt(s.Post)
+		}
+		b.jump(loop)
+		b.current = done
+
+	case *ast.RangeStmt:
+		b.add(s.X)
+		loop, body, done := b.newBlock(), b.newBlock(), b.newBlock()
+		b.jump(loop)
+		b.current = loop
+		b.branch(body, done)
+
+		b.current = body
+		b.add(s)
+		b.loopBody(s.Body, label, done, loop)
+		b.jump(loop)
+		b.current = done
+
+	case *ast.SwitchStmt:
+		if s.Init != nil {
+			b.stmt(s.Init)
+		}
+		if s.Tag != nil {
+			b.add(s.Tag)
+		}
+		b.switchBody(s.Body, label, true)
+
+	case *ast.TypeSwitchStmt:
+		if s.Init != nil {
+			b.stmt(s.Init)
+		}
+		b.stmt(s.Assign)
+		b.switchBody(s.Body, label, false)
+
+	case *ast.SelectStmt:
+		done := b.newBlock()
+		var clauses []*cfgBlock
+		for range s.Body.List {
+			clauses = append(clauses, b.newBlock())
+		}
+		if len(clauses) == 0 {
+			// An empty select blocks forever
+			b.branch()
+			b.current = done
+			break
+		}
+		b.branch(clauses...)
+
+		saved := b.saveTargets()
+		b.breakTarget = done
+		if label != nil {
+			label.breakTarget = done
+		}
+		for i, c := range s.Body.List {
+			clause := c.(*ast.CommClause)
+			b.current = clauses[i]
+			if clause.Comm != nil {
+				b.stmt(clause.Comm)
+			}
+			b.stmtList(clause.Body)
+			b.jump(done)
+		}
+		b.restoreTargets(saved)
+		b.current = done
+
+	case *ast.ReturnStmt:
+		b.add(s)
+		b.jump(b.cfg.exit)
+
+	case *ast.BranchStmt:
+		b.branchStmt(s)
+
+	case *ast.ExprStmt:
+		b.add(s)
+		if call, ok := s.X.(*ast.CallExpr); ok && b.noReturn(call) {
+			b.branch()
+		}
+
+	case *ast.EmptyStmt:
+
+	default:
+		// Assignments, declarations, sends, go, defer and the like
+		b.add(s)
+	}
+}
+
+type cfgTargets struct {
+	breakTarget, continueTarget, fallTarget *cfgBlock
+}
+
+func (b *cfgBuilder) saveTargets() cfgTargets {
+	return cfgTargets{b.breakTarget, b.continueTarget, b.fallTarget}
+}
+
+func (b *cfgBuilder) restoreTargets(t cfgTargets) {
+	b.breakTarget, b.continueTarget, b.fallTarget = t.breakTarget, t.continueTarget, t.fallTarget
+}
+
+func (b *cfgBuilder) loopBody(body *ast.BlockStmt, label *cfgLabel, breakTarget, continueTarget *cfgBlock) {
+	saved := b.saveTargets()
+	b.breakTarget, b.continueTarget = breakTarget, continueTarget
+	if label != nil {
+		label.breakTarget, label.continueTarget = breakTarget, continueTarget
+	}
+	b.stmt(body)
+	b.restoreTargets(saved)
+}
+
+// switchBody builds the case clauses of a switch. Case values are evaluated
+// in order until one matches, with the default clause taken last; a type
+// switch evaluates no values and may enter any clause.
+func (b *cfgBuilder) switchBody(body *ast.BlockStmt, label *cfgLabel, values bool) {
+	done := b.newBlock()
+	bodies := make([]*cfgBlock, len(body.List))
+	for i := range body.List {
+		bodies[i] = b.newBlock()
+	}
+
+	fallback := done
+	for i, c := range body.List {
+		if c.(*ast.CaseClause).List == nil {
+			fallback = bodies[i]
+		}
+	}
+
+	if values {
+		for i, c := range body.List {
+			clause := c.(*ast.CaseClause)
+			if clause.List == nil {
+				continue
+			}
+			for _, expr := range clause.List {
+				b.add(expr)
+			}
+			next := b.newBlock()
+			b.branch(bodies[i], next)
+			b.current = next
+		}
+		b.jump(fallback)
+	} else {
+		targets := append([]*cfgBlock(nil), bodies...)
+		if fallback == done {
+			targets = append(targets, done)
+		}
+		b.branch(targets...)
+	}
+
+	saved := b.saveTargets()
+	b.breakTarget = done
+	if label != nil {
+		label.breakTarget = done
+	}
+	for i, c := range body.List {
+		b.fallTarget = nil
+		if i+1 < len(bodies) {
+			b.fallTarget = bodies[i+1]
+		}
+		b.current = bodies[i]
+		b.stmtList(c.(*ast.CaseClause).Body)
+		b.jump(done)
+	}
+	b.restoreTargets(saved)
+	b.current = done
+}
+
+func (b *cfgBuilder) branchStmt(s *ast.BranchStmt) {
+	var target *cfgBlock
+	switch s.Tok {
+	case token.BREAK:
+		target = b.breakTarget
+		if s.Label != nil {
+			target = b.labelFor(s.Label.Name).breakTarget
+		}
+	case token.CONTINUE:
+		target = b.continueTarget
+		if s.Label != nil {
+			target = b.labelFor(s.Label.Name).continueTarget
+		}
+	case token.GOTO:
+		target = b.labelFor(s.Label.Name).block
+	case token.FALLTHROUGH:
+		target = b.fallTarget
+	}
+
+	if target == nil {
+		// A branch without a target does not compile; treat it as a dead end
+		b.branch()
+		return
+	}
+	b.jump(target)
+}
+
+// walk calls visit on each node reachable from the node at start in block,
+// following every path once. A path ends where visit returns true. It
+// reports whether some path reaches the exit.
+func (g *funcCFG) walk(block *cfgBlock, start int, visit func(ast.Node) bool) bool {
+	type pos struct {
+		block *cfgBlock
+		start int
+	}
+	visited := make(map[*cfgBlock]bool)
+	stack := []pos{{block, start}}
+	exited := false
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if p.block == g.exit {
+			exited = true
+		}
+		stopped := false
+		for _, node := range p.block.nodes[p.start:] {
+			if visit(node) {
+				stopped = true
+				break
+			}
+		}
+		if stopped {
+			continue
+		}
+
+		for _, succ := range p.block.succs {
+			if !visited[succ] {
+				visited[succ] = true
+				stack = append(stack, pos{succ, 0})
+			}
+		}
+	}
+	return exited
+}
@@ -0,0 +1,156 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// DefaultDiscardAllowlist names the calls whose errors may be discarded when
+// no allowlist is configured: printing to standard output and writing to
+// in-memory buffers, which cannot fail in practice.
+var DefaultDiscardAllowlist = []string{
+	"fmt.Print",
+	"fmt.Printf",
+	"fmt.Println",
+	"bytes.Buffer.Write",
+	"bytes.Buffer.WriteByte",
+	"bytes.Buffer.WriteRune",
+	"bytes.Buffer.WriteString",
+	"strings.Builder.Write",
+	"strings.Builder.WriteByte",
+	"strings.Builder.WriteRune",
+	"strings.Builder.WriteString",
+}
+
+// AnalyzeDiscardedErrors reports calls whose error result is dropped: call
+// statements, deferred calls and go statements whose callee returns an
+// error, and errors of any callee assigned to _, which error_handling leaves
+// to this rule when types are available. Calls matching an entry of allow
+// are accepted. Entries name functions as package.Func and methods as
+// package.Type.Method, with package the import path, and may end in * to
+// match a prefix, as in os.Remove*.
+//
+// The callee's signature comes from type information, so nothing is reported
+// for files that were not type-checked.
+func (a *Analyzer) AnalyzeDiscardedErrors(file *ast.File, allow []string) []Issue {
+	if !a.HasTypeInfo() {
+		return nil
+	}
+
+	var issues []Issue
+	report := func(node ast.Node, call *ast.CallExpr, how string) {
+		name := a.calleeName(call)
+		if allowed(allow, name) {
+			return
+		}
+		issue := Issue{
+			RuleID:      "discarded_errors",
+			Description: fmt.Sprintf("Error returned by %s is %s", name, how),
+			Severity:    "warning",
+			Position:    a.GetPositionOf(node),
+		}
+		if assign, ok := node.(*ast.AssignStmt); ok {
+			issue.SuggestedFixes = a.fixer(file).checkIgnoredError(assign)
+		}
+		issues = append(issues, issue)
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.ExprStmt:
+			if call, ok := node.X.(*ast.CallExpr); ok && a.returnsError(call) {
+				report(node, call, "discarded")
+			}
+		case *ast.DeferStmt:
+			if a.returnsError(node.Call) {
+				report(node, node.Call, "discarded by defer")
+			}
+		case *ast.GoStmt:
+			if a.returnsError(node.Call) {
+				report(node, node.Call, "discarded by go")
+			}
+		case *ast.AssignStmt:
+			if len(node.Rhs) != 1 {
+				break
+			}
+			call, ok := node.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				break
+			}
+			results := a.ResultTypes(call)
+			if len(results) != len(node.Lhs) {
+				break
+			}
+			for i, result := range results {
+				if isBlank(node.Lhs[i]) && types.Identical(result, errorType) {
+					report(node, call, "assigned to _")
+					break
+				}
+			}
+		}
+		return true
+	})
+
+	return issues
+}
+
+// returnsError reports whether one of the call's results is an error.
+func (a *Analyzer) returnsError(call *ast.CallExpr) bool {
+	for _, result := range a.ResultTypes(call) {
+		if types.Identical(result, errorType) {
+			return true
+		}
+	}
+	return false
+}
+
+// calleeName returns the allowlist name of a call's callee: package.Func for
+// functions and package.Type.Method for methods, or the callee expression
+// when it is not a declared function.
+func (a *Analyzer) calleeName(call *ast.CallExpr) string {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	}
+	if ident == nil || a.info == nil {
+		return types.ExprString(call.Fun)
+	}
+
+	fn, ok := a.info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return types.ExprString(call.Fun)
+	}
+
+	sig, _ := fn.Type().(*types.Signature)
+	if sig == nil || sig.Recv() == nil {
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	if named, ok := recv.(*types.Named); ok {
+		return fn.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name()
+	}
+	return fn.Pkg().Path() + "." + fn.Name()
+}
+
+// allowed reports whether name matches an allowlist entry.
+func allowed(allow []string, name string) bool {
+	for _, entry := range allow {
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if entry == name {
+			return true
+		}
+	}
+	return false
+}
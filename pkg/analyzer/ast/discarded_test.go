@@ -0,0 +1,97 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeDiscardedErrors(t *testing.T) {
+	code := `package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+func write(w io.Writer, f *os.File, v any) {
+	f.Close()
+	defer f.Close()
+	go f.Sync()
+	json.NewEncoder(w).Encode(v)
+	_ = os.Remove("tmp")
+	n, _ := w.Write(nil)
+	_ = n
+
+	var buf bytes.Buffer
+	buf.WriteString("ok")
+	buf.WriteTo(w)
+	fmt.Println("ok")
+	fmt.Fprintln(w, "checked")
+	if err := f.Close(); err != nil {
+		return
+	}
+	print(len("builtin"))
+}
+`
+
+	tests := []struct {
+		name  string
+		allow []string
+		want  []string
+	}{
+		{
+			name:  "default allowlist",
+			allow: DefaultDiscardAllowlist,
+			want: []string{
+				"Error returned by os.File.Close is discarded",
+				"Error returned by os.File.Close is discarded by defer",
+				"Error returned by os.File.Sync is discarded by go",
+				"Error returned by encoding/json.Encoder.Encode is discarded",
+				"Error returned by os.Remove is assigned to _",
+				"Error returned by io.Writer.Write is assigned to _",
+				"Error returned by bytes.Buffer.WriteTo is discarded",
+				"Error returned by fmt.Fprintln is discarded",
+			},
+		},
+		{
+			name:  "custom allowlist",
+			allow: []string{"os.File.*", "io.Writer.Write", "fmt.*", "bytes.Buffer.Write*", "encoding/json.Encoder.Encode"},
+			want: []string{
+				"Error returned by os.Remove is assigned to _",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{TypeCheck: true})
+			file, err := analyzer.ParseString("test.go", code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			var got []string
+			for _, issue := range analyzer.AnalyzeDiscardedErrors(file, tt.allow) {
+				got = append(got, issue.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDiscardedErrorsWithoutTypes(t *testing.T) {
+	analyzer := NewAnalyzer(AnalyzerConfig{})
+	file, err := analyzer.ParseString("test.go", "package test\n\nfunc f() {\n\tf.Close()\n}\n")
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	if issues := analyzer.AnalyzeDiscardedErrors(file, nil); len(issues) != 0 {
+		t.Errorf("Expected no issues without type information, got %+v", issues)
+	}
+}
@@ -0,0 +1,343 @@
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// errorDef is an assignment of an error value to a local variable that is
+// not inspected on some path before the variable is assigned again or the
+// function returns.
+type errorDef struct {
+	node ast.Node
+	name *ast.Ident
+	// overwritten is set when the value reaches a reassignment, rather than
+	// the function exit, without being inspected
+	overwritten bool
+}
+
+// uncheckedErrors tracks each error assignment in a function body through
+// its control-flow graph and returns those that reach a reassignment of the
+// variable or the function exit on some path without being read. Any read
+// counts as an inspection: a comparison, a switch case, a call such as
+// errors.Is, or returning it, but assigning it to _ does not. A bare return
+// reads the named results. Variables captured by closures or whose address
+// is taken are not tracked, since they may be read elsewhere; nested
+// function literals are analyzed on their own.
+func (a *Analyzer) uncheckedErrors(fn ast.Node, typ *ast.FuncType, body *ast.BlockStmt) []errorDef {
+	flow := &errorFlow{
+		a:       a,
+		fn:      fn,
+		escaped: a.escapedVars(body),
+		results: make(map[any]bool),
+	}
+	if typ.Results != nil {
+		for _, field := range typ.Results.List {
+			for _, name := range field.Names {
+				if key := a.varKey(name); key != nil {
+					flow.results[key] = true
+				}
+			}
+		}
+	}
+
+	cfg := buildCFG(body, a.isNoReturn)
+
+	var defs []errorDef
+	for _, block := range cfg.blocks {
+		for i, node := range block.nodes {
+			for _, name := range flow.checkedDefs(node) {
+				reached, overwritten := flow.unchecked(cfg, block, i+1, a.varKey(name))
+				if reached {
+					defs = append(defs, errorDef{node: node, name: name, overwritten: overwritten})
+				}
+			}
+		}
+	}
+	return defs
+}
+
+type errorFlow struct {
+	a  *Analyzer
+	fn ast.Node
+	// escaped holds the variables that closures capture or whose address
+	// is taken
+	escaped map[any]bool
+	// results holds the named results, which a bare return reads
+	results map[any]bool
+}
+
+// unchecked searches the paths from the node at start in block for one that
+// reaches a reassignment of the variable or the function exit without
+// reading it, preferring to report a reassignment.
+func (f *errorFlow) unchecked(cfg *funcCFG, block *cfgBlock, start int, key any) (reached, overwritten bool) {
+	type pos struct {
+		block *cfgBlock
+		start int
+	}
+	visited := make(map[*cfgBlock]bool)
+	stack := []pos{{block, start}}
+	exited := false
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		inspected := false
+		for _, node := range p.block.nodes[p.start:] {
+			if f.reads(node, key) {
+				inspected = true
+				break
+			}
+			if f.writes(node, key) {
+				return true, true
+			}
+		}
+		if inspected {
+			continue
+		}
+		if p.block == cfg.exit {
+			exited = true
+			continue
+		}
+
+		for _, succ := range p.block.succs {
+			if !visited[succ] {
+				visited[succ] = true
+				stack = append(stack, pos{succ, 0})
+			}
+		}
+	}
+	return exited, false
+}
+
+// checkedDefs returns the identifiers node assigns an error to that must be
+// inspected: local, untracked by closures, and not assigned nil.
+func (f *errorFlow) checkedDefs(node ast.Node) []*ast.Ident {
+	var names []*ast.Ident
+	add := func(name *ast.Ident, value ast.Expr) {
+		if name.Name == "_" || isNil(value) || !f.a.IsError(name) {
+			return
+		}
+		key := f.a.varKey(name)
+		if key == nil || f.escaped[key] || !f.local(name) {
+			return
+		}
+		names = append(names, name)
+	}
+
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		if n.Tok != token.ASSIGN && n.Tok != token.DEFINE {
+			break
+		}
+		for i, lhs := range n.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			value := n.Rhs[0]
+			if len(n.Rhs) == len(n.Lhs) {
+				value = n.Rhs[i]
+			}
+			add(ident, value)
+		}
+	case *ast.DeclStmt:
+		for _, spec := range valueSpecs(n) {
+			if len(spec.Values) == 0 {
+				continue
+			}
+			for i, name := range spec.Names {
+				value := spec.Values[0]
+				if len(spec.Values) == len(spec.Names) {
+					value = spec.Values[i]
+				}
+				add(name, value)
+			}
+		}
+	}
+	return names
+}
+
+// local reports whether the variable is declared in the analyzed function.
+func (f *errorFlow) local(name *ast.Ident) bool {
+	var pos token.Pos
+	if f.a.info != nil {
+		if obj := f.a.info.ObjectOf(name); obj != nil {
+			pos = obj.Pos()
+		}
+	} else if name.Obj != nil {
+		pos = name.Obj.Pos()
+	}
+	return pos.IsValid() && pos >= f.fn.Pos() && pos < f.fn.End()
+}
+
+// writes reports whether node assigns the variable.
+func (f *errorFlow) writes(node ast.Node, key any) bool {
+	var names []ast.Expr
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		names = n.Lhs
+	case *ast.RangeStmt:
+		names = []ast.Expr{n.Key, n.Value}
+	case *ast.DeclStmt:
+		for _, spec := range valueSpecs(n) {
+			for _, name := range spec.Names {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		if ident, ok := name.(*ast.Ident); ok && f.a.varKey(ident) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// reads reports whether node reads the variable.
+func (f *errorFlow) reads(node ast.Node, key any) bool {
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		for i, rhs := range n.Rhs {
+			// Assigning the error itself to _ discards it
+			if _, ok := rhs.(*ast.Ident); ok && len(n.Lhs) == len(n.Rhs) && isBlank(n.Lhs[i]) {
+				continue
+			}
+			if f.readsExpr(rhs, key) {
+				return true
+			}
+		}
+		for _, lhs := range n.Lhs {
+			// Compound assignments read their operands; an identifier
+			// is otherwise only written
+			if _, ok := lhs.(*ast.Ident); (!ok || (n.Tok != token.ASSIGN && n.Tok != token.DEFINE)) && f.readsExpr(lhs, key) {
+				return true
+			}
+		}
+		return false
+	case *ast.RangeStmt:
+		return false
+	case *ast.DeclStmt:
+		for _, spec := range valueSpecs(n) {
+			for _, value := range spec.Values {
+				if f.readsExpr(value, key) {
+					return true
+				}
+			}
+		}
+		return false
+	case *ast.ReturnStmt:
+		if len(n.Results) == 0 {
+			return f.results[key]
+		}
+	}
+	return f.readsExpr(node, key)
+}
+
+// readsExpr reports whether the variable is referenced within node, ignoring
+// field names and nested function literals.
+func (f *errorFlow) readsExpr(node ast.Node, key any) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.SelectorExpr:
+			found = f.readsExpr(n.X, key)
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); ok {
+				found = f.readsExpr(n.Value, key)
+				return false
+			}
+		case *ast.Ident:
+			found = f.a.varKey(n) == key
+		}
+		return true
+	})
+	return found
+}
+
+// escapedVars returns the variables referenced by the function literals in
+// body or whose address is taken there.
+func (a *Analyzer) escapedVars(body *ast.BlockStmt) map[any]bool {
+	escaped := make(map[any]bool)
+	mark := func(n ast.Node) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				if key := a.varKey(ident); key != nil {
+					escaped[key] = true
+				}
+			}
+			return true
+		})
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			mark(n.Body)
+			return false
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				mark(n.X)
+			}
+		}
+		return true
+	})
+	return escaped
+}
+
+// varKey identifies the variable an identifier refers to: its types.Object
+// when type information is available, otherwise the object the parser
+// resolved it to. It returns nil for identifiers that do not refer to a
+// local variable the parser could resolve.
+func (a *Analyzer) varKey(ident *ast.Ident) any {
+	if a.info != nil {
+		if obj := a.info.ObjectOf(ident); obj != nil {
+			return obj
+		}
+	}
+	if ident.Obj != nil && ident.Obj.Kind == ast.Var {
+		return ident.Obj
+	}
+	return nil
+}
+
+// isNoReturn reports whether control does not continue after the call.
+func (a *Analyzer) isNoReturn(call *ast.CallExpr) bool {
+	switch a.getFunctionName(call) {
+	case "panic", "os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln", "log.Panic", "log.Panicf", "log.Panicln":
+		return true
+	}
+	return false
+}
+
+func valueSpecs(decl *ast.DeclStmt) []*ast.ValueSpec {
+	gen, ok := decl.Decl.(*ast.GenDecl)
+	if !ok || gen.Tok != token.VAR {
+		return nil
+	}
+	var specs []*ast.ValueSpec
+	for _, spec := range gen.Specs {
+		if vs, ok := spec.(*ast.ValueSpec); ok {
+			specs = append(specs, vs)
+		}
+	}
+	return specs
+}
+
+func isNil(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+func isBlank(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
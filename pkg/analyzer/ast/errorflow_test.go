@@ -0,0 +1,246 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestErrorFlow(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		// lines are the lines of the reported assignments
+		lines       []int
+		overwritten bool
+	}{
+		{
+			name: "reassigned before one check",
+			code: `package test
+
+func f() error {
+	err := a()
+	err = b()
+	if err != nil {
+		return err
+	}
+	return nil
+}`,
+			lines:       []int{4},
+			overwritten: true,
+		},
+		{
+			name: "equality check",
+			code: `package test
+
+func f() {
+	err := a()
+	if err == nil {
+		ok()
+	}
+}`,
+		},
+		{
+			name: "tagless switch",
+			code: `package test
+
+func f() {
+	err := a()
+	switch {
+	case err != nil:
+		fail()
+	}
+}`,
+		},
+		{
+			name: "errors.Is",
+			code: `package test
+
+func f() bool {
+	err := a()
+	return errors.Is(err, ErrNotFound)
+}`,
+		},
+		{
+			name: "returned directly",
+			code: `package test
+
+func f() error {
+	_, err := a()
+	return err
+}`,
+		},
+		{
+			name: "bare return of a named result",
+			code: `package test
+
+func f() (err error) {
+	err = a()
+	return
+}`,
+		},
+		{
+			name: "checked in an if initializer",
+			code: `package test
+
+func f() {
+	if err := a(); err != nil {
+		fail()
+	}
+}`,
+		},
+		{
+			name: "checked on one branch only",
+			code: `package test
+
+func f(verbose bool) {
+	err := a()
+	if verbose {
+		log.Print(err)
+	}
+}`,
+			lines: []int{4},
+		},
+		{
+			name: "early return before the check",
+			code: `package test
+
+func f(skip bool) error {
+	err := a()
+	if skip {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}`,
+			lines: []int{4},
+		},
+		{
+			name: "overwritten on the next iteration",
+			code: `package test
+
+func f(items []string) {
+	var err error
+	for _, item := range items {
+		err = process(item)
+	}
+}`,
+			lines:       []int{6},
+			overwritten: true,
+		},
+		{
+			name: "checked after the loop",
+			code: `package test
+
+func f(items []string) error {
+	var err error
+	for _, item := range items {
+		if err = process(item); err != nil {
+			break
+		}
+	}
+	return err
+}`,
+		},
+		{
+			name: "panic ends the path",
+			code: `package test
+
+func f() {
+	err := a()
+	if err == nil {
+		return
+	}
+	panic(err)
+}`,
+		},
+		{
+			name: "captured by a deferred closure",
+			code: `package test
+
+func f() {
+	var err error
+	defer func() {
+		report(err)
+	}()
+	err = a()
+}`,
+		},
+		{
+			name: "unchecked in a function literal",
+			code: `package test
+
+func f() {
+	go func() {
+		err := a()
+		_ = err
+	}()
+}`,
+			lines: []int{5},
+		},
+		{
+			name: "nil assignment",
+			code: `package test
+
+func f() {
+	err := a()
+	if err != nil {
+		fail()
+	}
+	err = nil
+}`,
+		},
+		{
+			name: "field named err",
+			code: `package test
+
+func f(r *result) {
+	err := a()
+	r.err = nil
+	_ = result{err: nil}
+}`,
+			lines: []int{4},
+		},
+		{
+			name: "labeled continue",
+			code: `package test
+
+func f(groups [][]string) {
+outer:
+	for _, group := range groups {
+		for _, item := range group {
+			err := process(item)
+			if err != nil {
+				continue outer
+			}
+		}
+	}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			var lines []int
+			overwritten := false
+			for _, issue := range analyzer.AnalyzeErrorHandling(file) {
+				lines = append(lines, issue.Position.Line)
+				overwritten = overwritten || issue.Description != "Missing error check after error assignment"
+			}
+
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("Expected issues on lines %v, got %v", tt.lines, lines)
+			}
+			if len(lines) > 0 && overwritten != tt.overwritten {
+				t.Errorf("Expected overwritten: %v, got %v", tt.overwritten, overwritten)
+			}
+		})
+	}
+}
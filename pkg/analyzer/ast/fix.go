@@ -0,0 +1,805 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SuggestedFix is a change that resolves an issue, in the spirit of
+// golang.org/x/tools/go/analysis.SuggestedFix. Its edits all apply to the
+// file the issue was reported for.
+type SuggestedFix struct {
+	Message   string
+	TextEdits []TextEdit
+	// Refactor marks fixes that change a declaration, such as a function's
+	// name or signature, together with its references. They only cover the
+	// references within the package and should be applied deliberately.
+	Refactor bool
+}
+
+// TextEdit replaces the source between Pos and End with NewText. An edit
+// with Pos equal to End inserts NewText.
+type TextEdit struct {
+	Pos     token.Position
+	End     token.Position
+	NewText []byte
+}
+
+// fixer builds suggested fixes for one parsed file. A nil fixer, returned
+// when the file's source is unknown, builds none.
+type fixer struct {
+	a    *Analyzer
+	file *ast.File
+	tf   *token.File
+	src  []byte
+}
+
+func (a *Analyzer) fixer(file *ast.File) *fixer {
+	tf := a.fset.File(file.Pos())
+	if tf == nil {
+		return nil
+	}
+	src, ok := a.sources[tf.Name()]
+	if !ok || len(src) != tf.Size() {
+		return nil
+	}
+	return &fixer{a: a, file: file, tf: tf, src: src}
+}
+
+func (f *fixer) off(pos token.Pos) int {
+	return f.tf.Offset(pos)
+}
+
+func (f *fixer) text(node ast.Node) string {
+	return string(f.src[f.off(node.Pos()):f.off(node.End())])
+}
+
+func (f *fixer) insert(pos token.Pos, text string) TextEdit {
+	return f.replace(pos, pos, text)
+}
+
+func (f *fixer) replace(pos, end token.Pos, text string) TextEdit {
+	return TextEdit{
+		Pos:     f.a.fset.Position(pos),
+		End:     f.a.fset.Position(end),
+		NewText: []byte(text),
+	}
+}
+
+// indent returns the leading whitespace of the line holding pos.
+func (f *fixer) indent(pos token.Pos) string {
+	offset := f.off(pos)
+	start := offset
+	for start > 0 && f.src[start-1] != '\n' {
+		start--
+	}
+	line := f.src[start:offset]
+	return string(line[:len(line)-len(strings.TrimLeft(string(line), " \t"))])
+}
+
+// path returns the nodes enclosing node, outermost first, ending with node.
+func (f *fixer) path(node ast.Node) []ast.Node {
+	var path []ast.Node
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		if n == nil || n.Pos() > node.Pos() || n.End() < node.End() {
+			return false
+		}
+		path = append(path, n)
+		return n != node
+	})
+	return path
+}
+
+// enclosingFunc returns the type of the innermost function declaration or
+// literal on path.
+func enclosingFunc(path []ast.Node) *ast.FuncType {
+	for i := len(path) - 1; i >= 0; i-- {
+		switch n := path[i].(type) {
+		case *ast.FuncDecl:
+			return n.Type
+		case *ast.FuncLit:
+			return n.Type
+		}
+	}
+	return nil
+}
+
+// errorResults returns the zero values of a function's results other than
+// its trailing error, or false when the last result is not an error.
+func (f *fixer) errorResults(ft *ast.FuncType) ([]string, bool) {
+	if ft == nil || ft.Results == nil {
+		return nil, false
+	}
+
+	var results []ast.Expr
+	for _, field := range ft.Results.List {
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			results = append(results, field.Type)
+		}
+	}
+	if len(results) == 0 {
+		return nil, false
+	}
+	if ident, ok := results[len(results)-1].(*ast.Ident); !ok || ident.Name != "error" {
+		return nil, false
+	}
+
+	values := make([]string, 0, len(results))
+	for _, t := range results[:len(results)-1] {
+		values = append(values, f.zeroValue(t))
+	}
+	return values, true
+}
+
+// checkError adds an error check after an assignment whose error is never
+// inspected, returning the error wrapped from the enclosing function.
+func (f *fixer) checkError(assign *ast.AssignStmt) []SuggestedFix {
+	if f == nil {
+		return nil
+	}
+
+	errName := ""
+	for _, lhs := range assign.Lhs {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" && f.a.IsError(ident) {
+			errName = ident.Name
+		}
+	}
+	if errName == "" {
+		return nil
+	}
+
+	edits, ok := f.returnError(assign, errName)
+	if !ok {
+		return nil
+	}
+	return []SuggestedFix{{
+		Message:   fmt.Sprintf("Check %s and return it wrapped", errName),
+		TextEdits: edits,
+	}}
+}
+
+// checkIgnoredError assigns an error discarded with _ to err and adds a
+// check for it. It is only offered where err can be declared or assigned
+// without further changes.
+func (f *fixer) checkIgnoredError(assign *ast.AssignStmt) []SuggestedFix {
+	if f == nil {
+		return nil
+	}
+
+	index := -1
+	if call, ok := assign.Rhs[0].(*ast.CallExpr); ok && len(assign.Rhs) == 1 {
+		if results := f.a.ResultTypes(call); len(results) == len(assign.Lhs) {
+			for i, result := range results {
+				if types.Identical(result, errorType) {
+					index = i
+				}
+			}
+		}
+	}
+	if index < 0 {
+		// Errors are conventionally returned last
+		index = len(assign.Lhs) - 1
+	}
+	if ident, ok := assign.Lhs[index].(*ast.Ident); !ok || ident.Name != "_" {
+		return nil
+	}
+
+	others := false
+	for i, lhs := range assign.Lhs {
+		if ident, ok := lhs.(*ast.Ident); i != index && (!ok || ident.Name != "_") {
+			others = true
+		}
+	}
+
+	edits, ok := f.returnError(assign, "err")
+	if !ok {
+		return nil
+	}
+	blank := assign.Lhs[index]
+	edits = append(edits, f.replace(blank.Pos(), blank.End(), "err"))
+
+	visible, inBlock := errDeclared(f.path(assign), assign)
+	switch {
+	case assign.Tok == token.DEFINE:
+		// := needs a new variable on its left-hand side
+		if inBlock && !others {
+			return nil
+		}
+	case assign.Tok == token.ASSIGN && visible:
+	case assign.Tok == token.ASSIGN && !others:
+		// _ = f() becomes err := f()
+		edits = append(edits, f.replace(assign.TokPos, assign.TokPos+1, ":="))
+	default:
+		return nil
+	}
+
+	return []SuggestedFix{{
+		Message:   "Assign the error to err, check it and return it wrapped",
+		TextEdits: sortEdits(edits),
+	}}
+}
+
+// errDeclared reports whether a variable named err is visible at stmt, and
+// whether it was declared in the innermost block holding stmt.
+func errDeclared(path []ast.Node, stmt ast.Stmt) (visible, inBlock bool) {
+	for i := len(path) - 1; i >= 0; i-- {
+		switch n := path[i].(type) {
+		case *ast.BlockStmt:
+			for _, s := range n.List {
+				if s.Pos() >= stmt.Pos() {
+					break
+				}
+				if declaresErr(s) {
+					visible = true
+					inBlock = inBlock || i == len(path)-2
+				}
+			}
+		case *ast.FuncDecl:
+			return visible || declaresErrParam(n.Type), inBlock
+		case *ast.FuncLit:
+			return visible || declaresErrParam(n.Type), inBlock
+		}
+	}
+	return visible, inBlock
+}
+
+func declaresErrParam(ft *ast.FuncType) bool {
+	for _, list := range []*ast.FieldList{ft.Params, ft.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				if name.Name == "err" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func declaresErr(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if s.Tok != token.DEFINE {
+			return false
+		}
+		for _, lhs := range s.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "err" {
+				return true
+			}
+		}
+	case *ast.DeclStmt:
+		if gen, ok := s.Decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if name.Name == "err" {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// returnError returns the edits that check errName after stmt and return
+// it wrapped with fmt.Errorf, or false when the enclosing function does
+// not return an error.
+func (f *fixer) returnError(stmt *ast.AssignStmt, errName string) ([]TextEdit, bool) {
+	values, ok := f.errorResults(enclosingFunc(f.path(stmt)))
+	if !ok {
+		return nil, false
+	}
+	fmtName, edits := f.addImport("fmt")
+	values = append(values, fmt.Sprintf("%s.Errorf(%q, %s)", fmtName, callName(stmt)+": %w", errName))
+
+	indent := f.indent(stmt.Pos())
+	check := fmt.Sprintf("\n%sif %s != nil {\n%s\treturn %s\n%s}", indent, errName, indent, strings.Join(values, ", "), indent)
+
+	edits = append(edits, f.insert(stmt.End(), check))
+	return sortEdits(edits), true
+}
+
+// callName names the call on the right-hand side of an assignment for use
+// as error context, e.g. "os.ReadFile".
+func callName(assign *ast.AssignStmt) string {
+	for _, rhs := range assign.Rhs {
+		if call, ok := rhs.(*ast.CallExpr); ok {
+			switch fn := call.Fun.(type) {
+			case *ast.Ident:
+				return fn.Name
+			case *ast.SelectorExpr:
+				if x, ok := fn.X.(*ast.Ident); ok {
+					return x.Name + "." + fn.Sel.Name
+				}
+				return fn.Sel.Name
+			}
+		}
+	}
+	return "operation failed"
+}
+
+func (f *fixer) zeroValue(t ast.Expr) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return "false"
+		case "string":
+			return `""`
+		case "error", "any":
+			return "nil"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune":
+			return "0"
+		}
+		if t.Obj != nil {
+			if spec, ok := t.Obj.Decl.(*ast.TypeSpec); ok {
+				if _, ok := spec.Type.(*ast.StructType); ok {
+					return t.Name + "{}"
+				}
+			}
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return "nil"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "nil"
+		}
+		return f.text(t) + "{}"
+	case *ast.StructType:
+		return f.text(t) + "{}"
+	}
+	// The underlying type is unknown without type information
+	return "*new(" + f.text(t) + ")"
+}
+
+// addContext inserts ctx context.Context as the first parameter of decl and
+// passes a context at every call of the method in the file: the caller's
+// own context parameter, or context.TODO(). No fix is offered when decl
+// already has a context or ctx parameter, or when the package may refer to
+// the method in ways the fix cannot rewrite.
+func (f *fixer) addContext(decl *ast.FuncDecl, files []*ast.File) []SuggestedFix {
+	if f == nil || decl.Body == nil {
+		return nil
+	}
+
+	params := decl.Type.Params
+	for _, field := range params.List {
+		if f.a.IsContext(field.Type) {
+			return nil
+		}
+		for _, name := range field.Names {
+			if name.Name == "ctx" {
+				return nil
+			}
+		}
+	}
+	if mentions(decl.Body, "ctx") || f.usedElsewhere(decl, files) {
+		return nil
+	}
+
+	calls, ok := f.methodCalls(decl)
+	if !ok {
+		return nil
+	}
+
+	contextName, edits := f.addImport("context")
+	text := "ctx " + contextName + ".Context"
+	if len(params.List) > 0 {
+		text += ", "
+	}
+	edits = append(edits, f.insert(params.Opening+1, text))
+	for _, call := range calls {
+		arg := f.contextArg(call, contextName)
+		if len(call.Args) > 0 {
+			arg += ", "
+		}
+		edits = append(edits, f.insert(call.Lparen+1, arg))
+	}
+
+	return []SuggestedFix{{
+		Message:   fmt.Sprintf("Add ctx context.Context as the first parameter of %s", decl.Name.Name),
+		TextEdits: sortEdits(edits),
+		Refactor:  true,
+	}}
+}
+
+// methodCalls returns the calls of the method decl in the file. It reports
+// false when the method is used other than by calling it, or when, without
+// type information, a selector of its name cannot be told apart from a
+// method of another type.
+func (f *fixer) methodCalls(decl *ast.FuncDecl) ([]*ast.CallExpr, bool) {
+	var obj types.Object
+	if f.a.info != nil {
+		obj = f.a.info.Defs[decl.Name]
+	}
+
+	called := make(map[*ast.SelectorExpr]*ast.CallExpr)
+	var refs []*ast.SelectorExpr
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if sel, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok {
+				called[sel] = n
+			}
+		case *ast.SelectorExpr:
+			if n.Sel.Name != decl.Name.Name {
+				return true
+			}
+			if obj != nil {
+				if use := f.a.info.Uses[n.Sel]; use != nil && use != obj {
+					return true
+				}
+			}
+			refs = append(refs, n)
+		}
+		return true
+	})
+
+	calls := make([]*ast.CallExpr, 0, len(refs))
+	for _, sel := range refs {
+		call, ok := called[sel]
+		if !ok {
+			return nil, false
+		}
+		calls = append(calls, call)
+	}
+	return calls, true
+}
+
+// contextArg returns the context to pass at call: the context parameter of
+// the enclosing function if it has one, otherwise context.TODO() with the
+// context package imported as contextName.
+func (f *fixer) contextArg(call *ast.CallExpr, contextName string) string {
+	if ft := enclosingFunc(f.path(call)); ft != nil && ft.Params != nil {
+		for _, field := range ft.Params.List {
+			if f.a.IsContext(field.Type) && len(field.Names) == 1 && field.Names[0].Name != "_" {
+				return field.Names[0].Name
+			}
+		}
+	}
+	return contextName + ".TODO()"
+}
+
+// renameSnakeCase renames an unexported snake_case function, and its uses
+// in the file, to camelCase. No fix is offered when other files of the
+// package may refer to the function.
+func (f *fixer) renameSnakeCase(decl *ast.FuncDecl, files []*ast.File) []SuggestedFix {
+	if f == nil || ast.IsExported(decl.Name.Name) {
+		return nil
+	}
+
+	oldName := decl.Name.Name
+	newName := CamelCase(oldName)
+	if newName == oldName || newName == "" || !token.IsIdentifier(newName) || f.declares(newName) || f.usedElsewhere(decl, files) {
+		return nil
+	}
+
+	var obj types.Object
+	if f.a.info != nil {
+		obj = f.a.info.Defs[decl.Name]
+	}
+
+	var edits []TextEdit
+	seen := make(map[token.Pos]bool)
+	rename := func(ident *ast.Ident) {
+		if !seen[ident.Pos()] {
+			seen[ident.Pos()] = true
+			edits = append(edits, f.replace(ident.Pos(), ident.End(), newName))
+		}
+	}
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// Without type information every selector with the method's
+			// name is taken to refer to it; usedElsewhere made sure no
+			// other method or field has that name
+			if obj == nil && decl.Recv != nil && n.Sel.Name == oldName {
+				rename(n.Sel)
+			}
+		case *ast.Ident:
+			switch {
+			case n == decl.Name:
+				rename(n)
+			case obj != nil:
+				if f.a.info.Uses[n] == obj {
+					rename(n)
+				}
+			case decl.Recv == nil && n.Obj != nil && n.Obj == decl.Name.Obj:
+				rename(n)
+			}
+		}
+		return true
+	})
+
+	return []SuggestedFix{{
+		Message:   fmt.Sprintf("Rename %s to %s", oldName, newName),
+		TextEdits: sortEdits(edits),
+		Refactor:  true,
+	}}
+}
+
+// usedElsewhere reports whether changing decl could break code the fixer
+// does not edit: another file of the package mentions its name, or the
+// package declares another function, method, field or interface method of
+// that name.
+func (f *fixer) usedElsewhere(decl *ast.FuncDecl, files []*ast.File) bool {
+	name := decl.Name.Name
+	for _, file := range files {
+		if file != f.file && mentions(file, name) {
+			return true
+		}
+	}
+
+	found := false
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			found = found || n != decl && n.Name.Name == name
+		case *ast.Field:
+			for _, ident := range n.Names {
+				found = found || ident.Name == name
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// mentions reports whether an identifier named name occurs within node.
+func mentions(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// declares reports whether the file already declares name at the top level
+// or as a method.
+func (f *fixer) declares(name string) bool {
+	for _, decl := range f.file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name == name {
+				return true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.Name == name {
+						return true
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.Name == name {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// CamelCase converts a snake_case identifier to camelCase, keeping the case
+// of the first letter and upper-casing common initialisms such as ID.
+func CamelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString(part)
+			continue
+		}
+		if initialisms[strings.ToUpper(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"JSON": true, "SQL": true, "URL": true, "URI": true, "XML": true,
+}
+
+// addMutex adds a mu sync.Mutex field to the struct in the file that
+// declares the map field accessed by index.
+func (f *fixer) addMutex(index *ast.IndexExpr) []SuggestedFix {
+	if f == nil {
+		return nil
+	}
+	sel, ok := index.X.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+
+	var matches []*ast.TypeSpec
+	for _, decl := range f.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if st, ok := ts.Type.(*ast.StructType); ok && hasField(st, sel.Sel.Name) {
+				matches = append(matches, ts)
+			}
+		}
+	}
+	if len(matches) != 1 {
+		return nil
+	}
+	ts := matches[0]
+	st := ts.Type.(*ast.StructType)
+	if hasField(st, "mu") {
+		return nil
+	}
+
+	// The mutex goes first, set apart from the fields it guards
+	syncName, edits := f.addImport("sync")
+	field := "mu " + syncName + ".Mutex\n"
+	indent := "\t"
+	text := "\n" + indent + field
+	if fields := st.Fields.List; len(fields) > 0 {
+		indent = f.indent(fields[0].Pos())
+		text = "\n" + indent + field
+		if f.tf.Line(fields[0].Pos()) == f.tf.Line(st.Fields.Opening) {
+			text += indent
+		}
+	} else {
+		text += f.indent(ts.Pos())
+	}
+
+	edits = append(edits, f.insert(st.Fields.Opening+1, text))
+
+	return []SuggestedFix{{
+		Message:   fmt.Sprintf("Add a sync.Mutex field to %s", ts.Name.Name),
+		TextEdits: sortEdits(edits),
+	}}
+}
+
+func hasField(st *ast.StructType, name string) bool {
+	for _, field := range st.Fields.List {
+		for _, n := range field.Names {
+			if n.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wrapReturn wraps an error returned as result with fmt.Errorf, naming the
+// call it came from.
+func (f *fixer) wrapReturn(result ast.Expr, callee string) []SuggestedFix {
+	if f == nil {
+		return nil
+	}
+
+	fmtName, edits := f.addImport("fmt")
+	text := fmt.Sprintf("%s.Errorf(%s, %s)", fmtName, strconv.Quote(callee+": %w"), f.text(result))
+	edits = append(edits, f.replace(result.Pos(), result.End(), text))
+
+	return []SuggestedFix{{
+		Message:   "Wrap the error with fmt.Errorf",
+		TextEdits: sortEdits(edits),
+	}}
+}
+
+// useVerbW changes the verb at offset in the unquoted format to %w and
+// formats err in place of arg, which is err or err.Error().
+func (f *fixer) useVerbW(format *ast.BasicLit, offset int, arg, err ast.Expr) []SuggestedFix {
+	if f == nil || !strings.HasPrefix(format.Value, `"`) {
+		return nil
+	}
+	s, unquoteErr := strconv.Unquote(format.Value)
+	if unquoteErr != nil {
+		return nil
+	}
+
+	edits := []TextEdit{f.replace(format.Pos(), format.End(), strconv.Quote(s[:offset]+"w"+s[offset+1:]))}
+	if arg != err {
+		edits = append(edits, f.replace(arg.Pos(), arg.End(), f.text(err)))
+	}
+
+	return []SuggestedFix{{
+		Message:   "Wrap the error with %w",
+		TextEdits: sortEdits(edits),
+	}}
+}
+
+// useErrorsIs replaces a == or != comparison of err with sentinel by
+// errors.Is.
+func (f *fixer) useErrorsIs(expr *ast.BinaryExpr, err, sentinel ast.Expr) []SuggestedFix {
+	if f == nil {
+		return nil
+	}
+
+	errorsName, edits := f.addImport("errors")
+	text := fmt.Sprintf("%s.Is(%s, %s)", errorsName, f.text(err), f.text(sentinel))
+	if expr.Op == token.NEQ {
+		text = "!" + text
+	}
+	edits = append(edits, f.replace(expr.Pos(), expr.End(), text))
+
+	return []SuggestedFix{{
+		Message:   "Compare with errors.Is",
+		TextEdits: sortEdits(edits),
+	}}
+}
+
+// addImport returns the name the file refers to the package path by and
+// the edit that imports it, if the file does not already. An import under
+// an alias is used as it is; a blank or dot import gets an unaliased one
+// added next to it.
+func (f *fixer) addImport(path string) (string, []TextEdit) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	for _, spec := range f.file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != path {
+			continue
+		}
+		if spec.Name == nil {
+			return name, nil
+		}
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name, nil
+		}
+	}
+
+	quoted := strconv.Quote(path)
+	for _, decl := range f.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		if !gen.Lparen.IsValid() {
+			// import "x" becomes a block holding both imports
+			lines := []string{f.text(gen.Specs[0]), quoted}
+			sort.Strings(lines)
+			return name, []TextEdit{f.replace(gen.Pos(), gen.End(), "import (\n\t"+strings.Join(lines, "\n\t")+"\n)")}
+		}
+
+		// Insert before the first import that sorts after path
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if p, _ := strconv.Unquote(imp.Path.Value); p > path && imp.Name == nil {
+				return name, []TextEdit{f.insert(imp.Pos(), quoted+"\n"+f.indent(imp.Pos()))}
+			}
+		}
+		last := gen.Specs[len(gen.Specs)-1]
+		return name, []TextEdit{f.insert(last.End(), "\n"+f.indent(last.Pos())+quoted)}
+	}
+
+	return name, []TextEdit{f.insert(f.file.Name.End(), "\n\nimport "+quoted)}
+}
+
+func sortEdits(edits []TextEdit) []TextEdit {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Pos.Offset < edits[j].Pos.Offset
+	})
+	return edits
+}
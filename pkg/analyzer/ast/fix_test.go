@@ -0,0 +1,466 @@
+package ast
+
+import (
+	"go/ast"
+	"testing"
+)
+
+// applyFix applies a fix's edits, which are sorted and do not overlap.
+func applyFix(src string, fix SuggestedFix) string {
+	out := ""
+	last := 0
+	for _, edit := range fix.TextEdits {
+		out += src[last:edit.Pos.Offset] + string(edit.NewText)
+		last = edit.End.Offset
+	}
+	return out + src[last:]
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		analyze func(a *Analyzer, file *ast.File) []Issue
+		typed   bool
+		code    string
+		message string
+		want    string
+	}{
+		{
+			name:    "unchecked error",
+			analyze: (*Analyzer).AnalyzeErrorHandling,
+			code: `package test
+
+import "os"
+
+type config struct{ path string }
+
+func load(path string) (*config, config, [2]int, string, error) {
+	data, err := os.ReadFile(path)
+	return nil, config{string(data)}, [2]int{}, "", nil
+}
+`,
+			message: "Check err and return it wrapped",
+			want: `package test
+
+import (
+	"fmt"
+	"os"
+)
+
+type config struct{ path string }
+
+func load(path string) (*config, config, [2]int, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, config{}, [2]int{}, "", fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return nil, config{string(data)}, [2]int{}, "", nil
+}
+`,
+		},
+		{
+			name:    "fmt imported under an alias",
+			analyze: (*Analyzer).AnalyzeErrorHandling,
+			code: `package test
+
+import (
+	"os"
+
+	f "fmt"
+)
+
+func load(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	f.Println(len(data))
+	return data, nil
+}
+`,
+			message: "Check err and return it wrapped",
+			want: `package test
+
+import (
+	"os"
+
+	f "fmt"
+)
+
+func load(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, f.Errorf("os.ReadFile: %w", err)
+	}
+	f.Println(len(data))
+	return data, nil
+}
+`,
+		},
+		{
+			name:    "fmt imported for side effects",
+			analyze: (*Analyzer).AnalyzeErrorHandling,
+			code: `package test
+
+import _ "fmt"
+
+func load(open func() error) error {
+	err := open()
+	return nil
+}
+`,
+			message: "Check err and return it wrapped",
+			want: `package test
+
+import (
+	"fmt"
+	_ "fmt"
+)
+
+func load(open func() error) error {
+	err := open()
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	return nil
+}
+`,
+		},
+		{
+			name: "ignored error",
+			analyze: func(a *Analyzer, file *ast.File) []Issue {
+				return a.AnalyzeDiscardedErrors(file, DefaultDiscardAllowlist)
+			},
+			typed: true,
+			code: `package test
+
+import (
+	"os"
+)
+
+func closeAll(f *os.File) error {
+	_ = f.Close()
+	return nil
+}
+`,
+			message: "Assign the error to err, check it and return it wrapped",
+			want: `package test
+
+import (
+	"fmt"
+	"os"
+)
+
+func closeAll(f *os.File) error {
+	err := f.Close()
+	if err != nil {
+		return fmt.Errorf("f.Close: %w", err)
+	}
+	return nil
+}
+`,
+		},
+		{
+			name:    "missing context",
+			analyze: (*Analyzer).AnalyzeAPIDesign,
+			code: `package test
+
+type Service struct{}
+
+func (s *Service) Get() error {
+	return nil
+}
+`,
+			message: "Add ctx context.Context as the first parameter of Get",
+			want: `package test
+
+import "context"
+
+type Service struct{}
+
+func (s *Service) Get(ctx context.Context) error {
+	return nil
+}
+`,
+		},
+		{
+			name:    "missing context with callers",
+			analyze: (*Analyzer).AnalyzeAPIDesign,
+			typed:   true,
+			code: `package test
+
+import "context"
+
+type Service struct{}
+
+func (s *Service) Get(id string) error {
+	return nil
+}
+
+func handle(ctx context.Context, s *Service) error {
+	return s.Get("a")
+}
+
+func background(s *Service) error {
+	return s.Get("b")
+}
+`,
+			message: "Add ctx context.Context as the first parameter of Get",
+			want: `package test
+
+import "context"
+
+type Service struct{}
+
+func (s *Service) Get(ctx context.Context, id string) error {
+	return nil
+}
+
+func handle(ctx context.Context, s *Service) error {
+	return s.Get(ctx, "a")
+}
+
+func background(s *Service) error {
+	return s.Get(context.TODO(), "b")
+}
+`,
+		},
+		{
+			name:    "snake case method",
+			analyze: (*Analyzer).AnalyzeOrganizationStandards,
+			code: `package test
+
+type store struct{}
+
+func (s *store) load_by_id() {}
+
+func use(s *store) {
+	s.load_by_id()
+}
+`,
+			message: "Rename load_by_id to loadByID",
+			want: `package test
+
+type store struct{}
+
+func (s *store) loadByID() {}
+
+func use(s *store) {
+	s.loadByID()
+}
+`,
+		},
+		{
+			name:    "unwrapped return",
+			analyze: (*Analyzer).AnalyzeErrorWrapping,
+			code: `package test
+
+import "os"
+
+func load(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+`,
+			message: "Wrap the error with fmt.Errorf",
+			want: `package test
+
+import (
+	"fmt"
+	"os"
+)
+
+func load(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return data, nil
+}
+`,
+		},
+		{
+			name:    "error formatted with %v",
+			analyze: (*Analyzer).AnalyzeErrorWrapping,
+			typed:   true,
+			code: `package test
+
+import "fmt"
+
+func wrap(err error) error {
+	return fmt.Errorf("load %d: %v", 1, err.Error())
+}
+`,
+			message: "Wrap the error with %w",
+			want: `package test
+
+import "fmt"
+
+func wrap(err error) error {
+	return fmt.Errorf("load %d: %w", 1, err)
+}
+`,
+		},
+		{
+			name:    "sentinel comparison",
+			analyze: (*Analyzer).AnalyzeErrorWrapping,
+			typed:   true,
+			code: `package test
+
+import "io"
+
+func done(err error) bool {
+	return err != io.EOF
+}
+`,
+			message: "Compare with errors.Is",
+			want: `package test
+
+import (
+	"errors"
+	"io"
+)
+
+func done(err error) bool {
+	return !errors.Is(err, io.EOF)
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{TypeCheck: tt.typed})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			issues := tt.analyze(analyzer, file)
+			if len(issues) != 1 || len(issues[0].SuggestedFixes) != 1 {
+				t.Fatalf("expected one issue with one fix, got %+v", issues)
+			}
+			fix := issues[0].SuggestedFixes[0]
+			if fix.Message != tt.message {
+				t.Errorf("message = %q, want %q", fix.Message, tt.message)
+			}
+			if got := applyFix(tt.code, fix); got != tt.want {
+				t.Errorf("fixed source:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoSuggestedFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		analyze func(a *Analyzer, file *ast.File) []Issue
+		code    string
+	}{
+		{
+			name:    "function without error result",
+			analyze: (*Analyzer).AnalyzeErrorHandling,
+			code: `package test
+
+func load() {
+	data, err := read()
+	_ = data
+}
+`,
+		},
+		{
+			name:    "empty result list",
+			analyze: (*Analyzer).AnalyzeErrorHandling,
+			code: `package test
+
+func load() () {
+	data, err := read()
+	_ = data
+}
+`,
+		},
+		{
+			name:    "context not first",
+			analyze: (*Analyzer).AnalyzeAPIDesign,
+			code: `package test
+
+import "context"
+
+type Service struct{}
+
+func (s *Service) Get(id string, ctx context.Context) error {
+	return ctx.Err()
+}
+`,
+		},
+		{
+			name:    "method used as a value",
+			analyze: (*Analyzer).AnalyzeAPIDesign,
+			code: `package test
+
+type Service struct{}
+
+func (s *Service) Get() error {
+	return nil
+}
+
+func use(s *Service) func() error {
+	return s.Get
+}
+`,
+		},
+		{
+			name:    "exported snake case function",
+			analyze: (*Analyzer).AnalyzeOrganizationStandards,
+			code: `package test
+
+func Get_user() {}
+`,
+		},
+		{
+			name:    "rename would collide",
+			analyze: (*Analyzer).AnalyzeOrganizationStandards,
+			code: `package test
+
+func get_user() {}
+
+func getUser() {}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			issues := tt.analyze(analyzer, file)
+			if len(issues) == 0 {
+				t.Fatal("expected an issue")
+			}
+			for _, issue := range issues {
+				if len(issue.SuggestedFixes) != 0 {
+					t.Errorf("expected no fix, got %+v", issue.SuggestedFixes)
+				}
+			}
+		})
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	tests := map[string]string{
+		"get_user":     "getUser",
+		"Get_user_id":  "GetUserID",
+		"parse_json":   "parseJSON",
+		"load__config": "loadConfig",
+		"_private":     "private",
+	}
+	for in, want := range tests {
+		if got := CamelCase(in); got != want {
+			t.Errorf("CamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
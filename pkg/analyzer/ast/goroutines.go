@@ -0,0 +1,438 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"sort"
+)
+
+// AnalyzeGoroutines reports goroutines that can leak or race:
+//
+//   - a goroutine nothing can stop or wait for: it watches no context or
+//     channel, sends on or closes no channel and signals no WaitGroup
+//   - WaitGroup.Add called inside the goroutine it counts, which races with
+//     Wait
+//   - WaitGroup.Done called without defer, or never called by a goroutine
+//     started right after Add
+//   - a send on an unbuffered channel that blocks forever when the function
+//     that started the goroutine returns without receiving
+//   - a loop variable captured by a goroutine when goVersion, the language
+//     version of the module, is before go1.22 and every iteration shares it
+//
+// Functions started with go are checked when one of pkgFiles declares them.
+func (a *Analyzer) AnalyzeGoroutines(file *ast.File, pkgFiles []*ast.File, goVersion string) []Issue {
+	if len(pkgFiles) == 0 {
+		pkgFiles = []*ast.File{file}
+	}
+
+	var issues []Issue
+	report := func(node ast.Node, description string) {
+		issues = append(issues, Issue{
+			RuleID:      "goroutine_lifecycle",
+			Description: description,
+			Severity:    "warning",
+			Position:    a.GetPositionOf(node),
+		})
+	}
+
+	funcs := make(map[any]*ast.FuncDecl)
+	for _, f := range pkgFiles {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				funcs[a.declKey(fn)] = fn
+			}
+		}
+	}
+
+	sharedLoopVars := goVersion != "" && version.Compare(goVersion, "go1.22") < 0
+	captured := make(map[*ast.Ident]bool)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		var list []ast.Stmt
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			if node.Body != nil {
+				a.checkUnbufferedSends(node.Body, report)
+			}
+		case *ast.FuncLit:
+			a.checkUnbufferedSends(node.Body, report)
+		case *ast.ForStmt:
+			if sharedLoopVars {
+				a.checkLoopCapture(loopVars(node), node.Body, goVersion, captured, report)
+			}
+		case *ast.RangeStmt:
+			if sharedLoopVars && node.Tok == token.DEFINE {
+				a.checkLoopCapture([]ast.Expr{node.Key, node.Value}, node.Body, goVersion, captured, report)
+			}
+		case *ast.BlockStmt:
+			list = node.List
+		case *ast.CaseClause:
+			list = node.Body
+		case *ast.CommClause:
+			list = node.Body
+		}
+
+		for i, stmt := range list {
+			if g, ok := stmt.(*ast.GoStmt); ok {
+				var prev ast.Stmt
+				if i > 0 {
+					prev = list[i-1]
+				}
+				a.checkGoroutine(g, prev, funcs, report)
+			}
+		}
+		return true
+	})
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Position.Offset < issues[j].Position.Offset
+	})
+	return issues
+}
+
+// calledFunc returns the declaration of the function call invokes, if funcs
+// holds it. Without type information a method call is only resolved when a
+// single method of that name is declared.
+func (a *Analyzer) calledFunc(call *ast.CallExpr, funcs map[any]*ast.FuncDecl) *ast.FuncDecl {
+	ident := calleeIdent(call)
+	if ident == nil {
+		return nil
+	}
+	if a.info != nil {
+		if obj := a.info.ObjectOf(ident); obj != nil {
+			return funcs[obj]
+		}
+	}
+	if _, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+		return funcs[ident.Name]
+	}
+
+	var method *ast.FuncDecl
+	for _, fn := range funcs {
+		if fn.Recv != nil && fn.Name.Name == ident.Name {
+			if method != nil {
+				return nil
+			}
+			method = fn
+		}
+	}
+	return method
+}
+
+// checkGoroutine checks how the goroutine started by stmt can be stopped
+// and how it uses WaitGroups. prev is the statement before stmt, if any.
+func (a *Analyzer) checkGoroutine(stmt *ast.GoStmt, prev ast.Stmt, funcs map[any]*ast.FuncDecl, report func(ast.Node, string)) {
+	var body *ast.BlockStmt
+	lit, isLit := stmt.Call.Fun.(*ast.FuncLit)
+	if isLit {
+		body = lit.Body
+	} else if fn := a.calledFunc(stmt.Call, funcs); fn != nil {
+		body = fn.Body
+	}
+	if body == nil {
+		return
+	}
+
+	signals := false
+	done := false
+	deferred := make(map[*ast.CallExpr]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.GoStmt:
+			// Nested goroutines are checked on their own
+			return false
+		case *ast.DeferStmt:
+			deferred[node.Call] = true
+		case *ast.SendStmt, *ast.SelectStmt:
+			signals = true
+		case *ast.UnaryExpr:
+			signals = signals || node.Op == token.ARROW
+		case *ast.RangeStmt:
+			if t := a.TypeOf(node.X); t != nil {
+				_, isChan := t.Underlying().(*types.Chan)
+				signals = signals || isChan
+			}
+		case *ast.CallExpr:
+			if ident, ok := ast.Unparen(node.Fun).(*ast.Ident); ok && a.isBuiltin(ident, "close") {
+				signals = true
+			}
+			for _, arg := range node.Args {
+				if ident, ok := arg.(*ast.Ident); ok && ident.Name == "ctx" && !a.HasTypeInfo() || a.IsContext(arg) {
+					// The callee can watch the context
+					signals = true
+				}
+			}
+
+			sel, ok := ast.Unparen(node.Fun).(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			if !a.IsWaitGroup(sel.X) {
+				// ctx.Done() and ctx.Err(), or a similar done channel
+				if sel.Sel.Name == "Done" || sel.Sel.Name == "Err" && a.IsContext(sel.X) {
+					signals = true
+				}
+				break
+			}
+
+			wg := types.ExprString(sel.X)
+			switch sel.Sel.Name {
+			case "Add":
+				if isLit {
+					report(node, fmt.Sprintf("%s.Add is called inside the goroutine it counts and may run after %s.Wait; call it before the go statement", wg, wg))
+				}
+			case "Done":
+				signals, done = true, true
+				if !deferred[node] {
+					report(node, fmt.Sprintf("%s.Done is not deferred; a panic or early return skips it and %s.Wait blocks forever", wg, wg))
+				}
+			case "Wait":
+				signals = true
+			}
+		}
+		return true
+	})
+
+	if wg := a.waitGroupAdd(prev); wg != "" && !done {
+		report(stmt, fmt.Sprintf("Goroutine started after %s.Add never calls %s.Done", wg, wg))
+	} else if !signals {
+		report(stmt, "Goroutine has no cancellation path: it watches no context or channel and signals no WaitGroup")
+	}
+}
+
+// waitGroupAdd returns the WaitGroup that stmt calls Add on, or "".
+func (a *Analyzer) waitGroupAdd(stmt ast.Stmt) string {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return ""
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Add" || !a.IsWaitGroup(sel.X) {
+		return ""
+	}
+	return types.ExprString(sel.X)
+}
+
+// checkUnbufferedSends reports sends on unbuffered channels made in body by
+// goroutines it starts, when some path through body returns without
+// receiving from the channel. The goroutine then blocks forever.
+func (a *Analyzer) checkUnbufferedSends(body *ast.BlockStmt, report func(ast.Node, string)) {
+	chans := make(map[any]*ast.Ident)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if assign, ok := n.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE && len(assign.Lhs) == len(assign.Rhs) {
+			for i, rhs := range assign.Rhs {
+				if ident, ok := assign.Lhs[i].(*ast.Ident); ok && isUnbufferedChan(rhs) {
+					if key := a.varKey(ident); key != nil {
+						chans[key] = ident
+					}
+				}
+			}
+		}
+		return true
+	})
+	if len(chans) == 0 {
+		return
+	}
+	for key := range chans {
+		if a.chanEscapes(body, key) {
+			delete(chans, key)
+		}
+	}
+
+	cfg := buildCFG(body, a.isNoReturn)
+	for _, block := range cfg.blocks {
+		for i, node := range block.nodes {
+			g, ok := node.(*ast.GoStmt)
+			if !ok {
+				continue
+			}
+			lit, ok := g.Call.Fun.(*ast.FuncLit)
+			if !ok {
+				continue
+			}
+
+			for key, ch := range chans {
+				sends := a.blockingSends(lit.Body, key)
+				if len(sends) == 0 {
+					continue
+				}
+				exited := cfg.walk(block, i+1, func(n ast.Node) bool {
+					return a.receives(n, key)
+				})
+				if exited {
+					for _, send := range sends {
+						report(send, fmt.Sprintf("Send on unbuffered channel %s blocks forever if the function that started the goroutine returns without receiving; buffer the channel or select on cancellation", ch.Name))
+					}
+				}
+			}
+		}
+	}
+}
+
+// blockingSends returns the sends on the channel in body that are not
+// select cases.
+func (a *Analyzer) blockingSends(body *ast.BlockStmt, key any) []*ast.SendStmt {
+	var sends []*ast.SendStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectStmt:
+			return false
+		case *ast.SendStmt:
+			if ident, ok := node.Chan.(*ast.Ident); ok && a.varKey(ident) == key {
+				sends = append(sends, node)
+			}
+		}
+		return true
+	})
+	return sends
+}
+
+// receives reports whether a control-flow node receives from the channel,
+// leaving aside function literals.
+func (a *Analyzer) receives(node ast.Node, key any) bool {
+	if r, ok := node.(*ast.RangeStmt); ok {
+		// The node stands for the range assignment; the body is elsewhere
+		ident, ok := r.X.(*ast.Ident)
+		return ok && a.varKey(ident) == key
+	}
+
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch expr := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.UnaryExpr:
+			if ident, ok := expr.X.(*ast.Ident); ok && expr.Op == token.ARROW && a.varKey(ident) == key {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// chanEscapes reports whether the channel is used in body other than by
+// sends, receives and range loops: passed to a function, returned, stored
+// or assigned, after which other code may receive from it.
+func (a *Analyzer) chanEscapes(body *ast.BlockStmt, key any) bool {
+	isChan := func(expr ast.Expr) bool {
+		ident, ok := ast.Unparen(expr).(*ast.Ident)
+		return ok && a.varKey(ident) == key
+	}
+	anyChan := func(exprs []ast.Expr) bool {
+		for _, expr := range exprs {
+			if isChan(expr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	escapes := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CallExpr:
+			if ident, ok := ast.Unparen(node.Fun).(*ast.Ident); ok && (a.isBuiltin(ident, "len") || a.isBuiltin(ident, "cap")) {
+				break
+			}
+			escapes = escapes || anyChan(node.Args)
+		case *ast.ReturnStmt:
+			escapes = escapes || anyChan(node.Results)
+		case *ast.AssignStmt:
+			escapes = escapes || anyChan(node.Rhs)
+		case *ast.ValueSpec:
+			escapes = escapes || anyChan(node.Values)
+		case *ast.CompositeLit:
+			escapes = escapes || anyChan(node.Elts)
+		case *ast.KeyValueExpr:
+			escapes = escapes || isChan(node.Value)
+		case *ast.SendStmt:
+			escapes = escapes || isChan(node.Value)
+		case *ast.UnaryExpr:
+			escapes = escapes || node.Op == token.AND && isChan(node.X)
+		}
+		return !escapes
+	})
+	return escapes
+}
+
+// isUnbufferedChan reports whether expr makes a channel without a buffer.
+func isUnbufferedChan(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+	if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "make" {
+		return false
+	}
+	if _, ok := call.Args[0].(*ast.ChanType); !ok {
+		return false
+	}
+	if len(call.Args) == 1 {
+		return true
+	}
+	size, ok := call.Args[1].(*ast.BasicLit)
+	return ok && size.Value == "0"
+}
+
+// loopVars returns the variables a for statement declares in its init
+// statement.
+func loopVars(loop *ast.ForStmt) []ast.Expr {
+	if assign, ok := loop.Init.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+		return assign.Lhs
+	}
+	return nil
+}
+
+// checkLoopCapture reports uses of the loop variables vars inside
+// goroutines started by function literals in the loop body. captured
+// records the uses already reported, for loops nested in one another.
+func (a *Analyzer) checkLoopCapture(vars []ast.Expr, body *ast.BlockStmt, goVersion string, captured map[*ast.Ident]bool, report func(ast.Node, string)) {
+	keys := make(map[any]bool)
+	for _, v := range vars {
+		if ident, ok := v.(*ast.Ident); ok && ident.Name != "_" {
+			if key := a.varKey(ident); key != nil {
+				keys[key] = true
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		g, ok := n.(*ast.GoStmt)
+		if !ok {
+			return true
+		}
+		lit, ok := g.Call.Fun.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+
+		reported := make(map[any]bool)
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok || captured[ident] {
+				return true
+			}
+			if key := a.varKey(ident); keys[key] && !reported[key] {
+				reported[key] = true
+				captured[ident] = true
+				report(ident, fmt.Sprintf("Goroutine captures loop variable %s, which every iteration shares before Go 1.22 (the module uses %s); pass it as an argument", ident.Name, goVersion))
+			}
+			return true
+		})
+		return true
+	})
+}
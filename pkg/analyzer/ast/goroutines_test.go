@@ -0,0 +1,303 @@
+package ast
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeGoroutines(t *testing.T) {
+	tests := []struct {
+		name      string
+		typed     bool
+		goVersion string
+		code      string
+		want      []string
+	}{
+		{
+			name: "no cancellation path",
+			code: `package test
+
+func start(jobs []string) {
+	go func() {
+		for {
+			poll()
+		}
+	}()
+}
+`,
+			want: []string{"Goroutine has no cancellation path: it watches no context or channel and signals no WaitGroup"},
+		},
+		{
+			name: "stopped by a context or a channel",
+			code: `package test
+
+func start(ctx context.Context, quit chan struct{}, results chan int) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				poll()
+			}
+		}
+	}()
+	go func() {
+		<-quit
+	}()
+	go func() {
+		results <- compute()
+	}()
+	go serve(ctx)
+	go loop()
+}
+
+func loop() {
+	for {
+		if stopped.Load() {
+			close(done)
+			return
+		}
+	}
+}
+`,
+		},
+		{
+			name: "WaitGroup misuse",
+			code: `package test
+
+func run(items []string) {
+	var wg sync.WaitGroup
+	for _, item := range items {
+		go func(item string) {
+			wg.Add(1)
+			process(item)
+			wg.Done()
+		}(item)
+	}
+	wg.Add(1)
+	go func() {
+		process("last")
+	}()
+	wg.Wait()
+}
+`,
+			want: []string{
+				"wg.Add is called inside the goroutine it counts and may run after wg.Wait; call it before the go statement",
+				"wg.Done is not deferred; a panic or early return skips it and wg.Wait blocks forever",
+				"Goroutine started after wg.Add never calls wg.Done",
+			},
+		},
+		{
+			name:  "WaitGroup used correctly",
+			typed: true,
+			code: `package test
+
+import "sync"
+
+func run(items []string, process func(string)) {
+	var wg sync.WaitGroup
+	for _, item := range items {
+		wg.Add(1)
+		go worker(&wg, item, process)
+	}
+	wg.Wait()
+}
+
+func worker(group *sync.WaitGroup, item string, process func(string)) {
+	defer group.Done()
+	process(item)
+}
+`,
+		},
+		{
+			name: "unbuffered send abandoned on timeout",
+			code: `package test
+
+func fetch(ctx context.Context) (string, error) {
+	ch := make(chan string)
+	go func() {
+		ch <- download()
+	}()
+	select {
+	case body := <-ch:
+		return body, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+`,
+			want: []string{"Send on unbuffered channel ch blocks forever if the function that started the goroutine returns without receiving; buffer the channel or select on cancellation"},
+		},
+		{
+			name: "unbuffered send always received",
+			code: `package test
+
+func fetch(urls []string) []string {
+	if len(urls) == 0 {
+		return nil
+	}
+	ch := make(chan string)
+	buffered := make(chan string, 1)
+	go func() {
+		ch <- download()
+		buffered <- download()
+	}()
+	return []string{<-ch}
+}
+`,
+		},
+		{
+			name: "early return before receiving",
+			code: `package test
+
+func fetch(skip bool) string {
+	ch := make(chan string)
+	go func() {
+		ch <- download()
+	}()
+	if skip {
+		return ""
+	}
+	return <-ch
+}
+`,
+			want: []string{"Send on unbuffered channel ch blocks forever if the function that started the goroutine returns without receiving; buffer the channel or select on cancellation"},
+		},
+		{
+			name: "channel handed to the caller",
+			code: `package test
+
+func fetch() <-chan string {
+	ch := make(chan string)
+	go func() {
+		ch <- download()
+	}()
+	return ch
+}
+`,
+		},
+		{
+			name:      "loop variables before Go 1.22",
+			goVersion: "go1.21",
+			code: `package test
+
+func run(items []string, results chan string) {
+	for i := 0; i < len(items); i++ {
+		go func() {
+			results <- items[i]
+		}()
+	}
+	for _, item := range items {
+		item := item
+		go func() {
+			results <- item
+		}()
+	}
+	for _, item := range items {
+		go func() {
+			results <- item + item
+		}()
+	}
+}
+`,
+			want: []string{
+				"Goroutine captures loop variable i, which every iteration shares before Go 1.22 (the module uses go1.21); pass it as an argument",
+				"Goroutine captures loop variable item, which every iteration shares before Go 1.22 (the module uses go1.21); pass it as an argument",
+			},
+		},
+		{
+			name:      "loop variables from Go 1.22",
+			goVersion: "go1.22",
+			code: `package test
+
+func run(items []string, results chan string) {
+	for _, item := range items {
+		go func() {
+			results <- item
+		}()
+	}
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{TypeCheck: tt.typed})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			var got []string
+			for _, issue := range analyzer.AnalyzeGoroutines(file, nil, tt.goVersion) {
+				got = append(got, issue.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestModuleGoVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		gomod string
+		want  string
+	}{
+		{
+			name:  "go directive",
+			gomod: "module example.com/m\n\ngo 1.21\n",
+			want:  "go1.21",
+		},
+		{
+			name:  "patch release and comment",
+			gomod: "module example.com/m\n\ngo 1.22.3 // pinned\n\ntoolchain go1.23.0\n",
+			want:  "go1.22.3",
+		},
+		{
+			name:  "no go directive",
+			gomod: "module example.com/m\n",
+			want:  "go1.16",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(tt.gomod), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(root, "internal", "worker")
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ModuleGoVersion(dir); got != tt.want {
+				t.Errorf("ModuleGoVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleGoVersionCached(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(gomod, []byte("module example.com/m\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ModuleGoVersion(dir); got != "go1.21" {
+		t.Fatalf("ModuleGoVersion() = %q, want go1.21", got)
+	}
+
+	if err := os.Remove(gomod); err != nil {
+		t.Fatal(err)
+	}
+	if got := ModuleGoVersion(dir); got != "go1.21" {
+		t.Errorf("ModuleGoVersion() = %q after go.mod was removed, want the cached go1.21", got)
+	}
+}
@@ -0,0 +1,227 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/version"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SourceFile is a parsed Go file together with its source.
+type SourceFile struct {
+	Path string
+	Src  []byte
+	AST  *ast.File
+}
+
+// Package is the set of files in one directory that share a package clause.
+// External test files (package foo_test) form their own Package.
+type Package struct {
+	Dir   string
+	Name  string
+	Files []*SourceFile
+}
+
+// ASTFiles returns the syntax trees of the package's files.
+func (p *Package) ASTFiles() []*ast.File {
+	files := make([]*ast.File, len(p.Files))
+	for i, f := range p.Files {
+		files[i] = f.AST
+	}
+	return files
+}
+
+// ParsePackages parses the Go files in dir that match the current build
+// context, honoring build constraints and file name suffixes. Test files are
+// only included when IncludeTests is set. With TypeCheck enabled each package
+// is type-checked as a whole.
+func (a *Analyzer) ParsePackages(dir string) ([]*Package, error) {
+	paths, err := GoFiles(dir, a.config.IncludeTests)
+	if err != nil {
+		return nil, err
+	}
+	return a.ParseFiles(paths)
+}
+
+// GoFiles returns the Go files in dir that match the current build context,
+// honoring build constraints and file name suffixes. Test files are only
+// included when includeTests is set.
+func GoFiles(dir string, includeTests bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !includeTests {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return paths, nil
+}
+
+// ParseFiles parses the given files and groups them into packages by
+// directory and package clause. With TypeCheck enabled each package is
+// type-checked as a whole.
+func (a *Analyzer) ParseFiles(paths []string) ([]*Package, error) {
+	srcs := make([][]byte, len(paths))
+	for i, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		srcs[i] = src
+	}
+	return a.parseSources(paths, srcs)
+}
+
+// ParseSources is like ParseFiles for sources that are already in memory,
+// keyed by their paths.
+func (a *Analyzer) ParseSources(sources map[string][]byte) ([]*Package, error) {
+	paths := make([]string, 0, len(sources))
+	for path := range sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	srcs := make([][]byte, len(paths))
+	for i, path := range paths {
+		srcs[i] = sources[path]
+	}
+	return a.parseSources(paths, srcs)
+}
+
+func (a *Analyzer) parseSources(paths []string, srcs [][]byte) ([]*Package, error) {
+	type key struct{ dir, name string }
+	byKey := make(map[key]*Package)
+
+	for i, path := range paths {
+		src := srcs[i]
+		file, err := parser.ParseFile(a.fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file: %w", err)
+		}
+
+		k := key{filepath.Dir(path), file.Name.Name}
+		pkg, ok := byKey[k]
+		if !ok {
+			pkg = &Package{Dir: k.dir, Name: k.name}
+			byKey[k] = pkg
+		}
+		pkg.Files = append(pkg.Files, &SourceFile{Path: path, Src: src, AST: file})
+		a.sources[path] = src
+	}
+
+	pkgs := make([]*Package, 0, len(byKey))
+	for _, pkg := range byKey {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Dir != pkgs[j].Dir {
+			return pkgs[i].Dir < pkgs[j].Dir
+		}
+		return pkgs[i].Name < pkgs[j].Name
+	})
+
+	if a.config.TypeCheck {
+		for _, pkg := range pkgs {
+			a.TypeCheck(pkg.ASTFiles()...)
+		}
+	}
+
+	return pkgs, nil
+}
+
+// PackageDirs returns every directory under root that may hold a package of
+// the module rooted there. Hidden directories, vendor, testdata and nested
+// modules are skipped.
+func PackageDirs(root string) ([]string, error) {
+	var dirs []string
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		dirs = append(dirs, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk module: %w", err)
+	}
+
+	return dirs, nil
+}
+
+// moduleGoVersions caches ModuleGoVersion by absolute directory.
+var moduleGoVersions sync.Map
+
+// ModuleGoVersion returns the language version, such as go1.21, set by the
+// go directive of the go.mod file governing dir, or "" when dir is not in a
+// module. A go.mod without a go directive means go1.16. Results are cached
+// per directory for the life of the process.
+func ModuleGoVersion(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	if v, ok := moduleGoVersions.Load(dir); ok {
+		return v.(string)
+	}
+
+	v := ""
+	for d := dir; ; {
+		data, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			v = goDirective(data)
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	moduleGoVersions.Store(dir, v)
+	return v
+}
+
+func goDirective(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "go" {
+			if v := "go" + fields[1]; version.IsValid(v) {
+				return v
+			}
+		}
+	}
+	return "go1.16"
+}
@@ -0,0 +1,418 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// DefaultPanicAllowlist names the functions that may panic when no
+// allowlist is configured: Must constructors such as regexp.MustCompile,
+// which panic by convention on input known when the program is written.
+var DefaultPanicAllowlist = []string{
+	"Must*",
+}
+
+// panicScope says where a panic raised at a call goes.
+type panicScope int
+
+const (
+	// scopeCaller panics unwind into the function's caller
+	scopeCaller panicScope = iota
+	// scopeRecovered panics are stopped by a recover the function defers
+	scopeRecovered
+	// scopeDetached panics crash the program: they are raised on a new
+	// goroutine, or in a function value that may be called anywhere
+	scopeDetached
+)
+
+// funcCall is a call of a function declared in the file being analyzed.
+type funcCall struct {
+	caller any
+	scope  panicScope
+}
+
+// AnalyzePanics reports calls of panic, os.Exit and log.Fatal outside main
+// packages, init functions and test files. A library should return errors
+// and leave ending the process to its main package.
+//
+// A panic is accepted when a deferred recover stops it before it leaves
+// the package: the function raising it defers a recover, or it is an
+// unexported function only called where a recover would stop its panics.
+// os.Exit and log.Fatal cannot be recovered. Functions matching an entry
+// of allow, by name or as Type.Method, are not checked; entries may end in
+// * to match a prefix, as in Must*.
+func (a *Analyzer) AnalyzePanics(file *ast.File, allow []string) []Issue {
+	if file.Name.Name == "main" || strings.HasSuffix(a.fset.Position(file.Pos()).Filename, "_test.go") {
+		return nil
+	}
+
+	funcs := a.indexFuncs(file)
+	decls := funcs.decls
+
+	// Find how each function is called, and which are used as values
+	calls := make(map[any][]funcCall)
+	callees := make(map[*ast.Ident]bool)
+	for key, fn := range decls {
+		a.inspectCalls(fn.Body, scopeCaller, funcs, func(call *ast.CallExpr, scope panicScope) {
+			if ident := calleeIdent(call); ident != nil {
+				callees[ident] = true
+				for _, callee := range funcs.refKeys(ident) {
+					calls[callee] = append(calls[callee], funcCall{caller: key, scope: scope})
+				}
+			}
+		})
+	}
+	escaped := make(map[any]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && !callees[ident] && !funcs.declared[ident] {
+			for _, key := range funcs.refKeys(ident) {
+				escaped[key] = true
+			}
+		}
+		return true
+	})
+
+	// An unexported function's panics are recovered if every call of it is
+	recovered := make(map[any]bool)
+	for changed := true; changed; {
+		changed = false
+		for key, fn := range decls {
+			if recovered[key] || ast.IsExported(fn.Name.Name) || escaped[key] || len(calls[key]) == 0 {
+				continue
+			}
+			covered := true
+			for _, c := range calls[key] {
+				if c.scope == scopeDetached || c.scope == scopeCaller && !recovered[c.caller] {
+					covered = false
+					break
+				}
+			}
+			if covered {
+				recovered[key] = true
+				changed = true
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || fn.Recv == nil && fn.Name.Name == "init" || allowed(allow, fn.Name.Name) || allowed(allow, funcName(fn)) {
+			continue
+		}
+
+		key := a.declKey(fn)
+		a.inspectCalls(fn.Body, scopeCaller, funcs, func(call *ast.CallExpr, scope panicScope) {
+			name := a.exitCall(call)
+			switch {
+			case name == "":
+				return
+			case name != "panic":
+				issues = append(issues, Issue{
+					RuleID:      "no_panic",
+					Description: fmt.Sprintf("%s ends the process outside package main; return an error instead", name),
+					Severity:    "error",
+					Position:    a.GetPositionOf(call),
+				})
+			case scope == scopeDetached || scope == scopeCaller && !recovered[key]:
+				issues = append(issues, Issue{
+					RuleID:      "no_panic",
+					Description: fmt.Sprintf("panic in %s escapes package %s; return an error instead", funcName(fn), file.Name.Name),
+					Severity:    "error",
+					Position:    a.GetPositionOf(call),
+				})
+			}
+		})
+	}
+
+	return issues
+}
+
+// inspectCalls calls visit for each call in body with the scope of a panic
+// raised there, given the scope of panics in the enclosing code.
+func (a *Analyzer) inspectCalls(body *ast.BlockStmt, scope panicScope, funcs *funcIndex, visit func(*ast.CallExpr, panicScope)) {
+	if scope == scopeCaller && a.defersRecover(body, funcs) {
+		scope = scopeRecovered
+	}
+
+	var inspect func(ast.Node) bool
+	inspect = func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.GoStmt:
+			if lit, ok := node.Call.Fun.(*ast.FuncLit); ok {
+				a.inspectCalls(lit.Body, scopeDetached, funcs, visit)
+			} else {
+				visit(node.Call, scopeDetached)
+			}
+			for _, arg := range node.Call.Args {
+				ast.Inspect(arg, inspect)
+			}
+			return false
+
+		case *ast.DeferStmt:
+			lit, ok := node.Call.Fun.(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			// A panic in the function that recovers is not recovered
+			litScope := scope
+			if scope == scopeRecovered && a.callsRecover(lit.Body) {
+				litScope = scopeCaller
+			}
+			a.inspectCalls(lit.Body, litScope, funcs, visit)
+			for _, arg := range node.Call.Args {
+				ast.Inspect(arg, inspect)
+			}
+			return false
+
+		case *ast.CallExpr:
+			if lit, ok := node.Fun.(*ast.FuncLit); ok {
+				a.inspectCalls(lit.Body, scope, funcs, visit)
+				for _, arg := range node.Args {
+					ast.Inspect(arg, inspect)
+				}
+				return false
+			}
+			visit(node, scope)
+
+		case *ast.FuncLit:
+			a.inspectCalls(node.Body, scopeDetached, funcs, visit)
+			return false
+		}
+		return true
+	}
+	ast.Inspect(body, inspect)
+}
+
+// defersRecover reports whether body defers a call that recovers: a
+// function literal calling recover, or a function of the file that does.
+func (a *Analyzer) defersRecover(body *ast.BlockStmt, funcs *funcIndex) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			if lit, ok := node.Call.Fun.(*ast.FuncLit); ok {
+				found = found || a.callsRecover(lit.Body)
+			} else if ident := calleeIdent(node.Call); ident != nil {
+				found = found || funcs.recovers(ident)
+			}
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// callsRecover reports whether body calls recover directly, rather than in
+// a nested function literal.
+func (a *Analyzer) callsRecover(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if ident, ok := ast.Unparen(node.Fun).(*ast.Ident); ok && a.isBuiltin(ident, "recover") {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// exitCall returns the name of the function a call to panic, os.Exit or
+// log.Fatal invokes, or "" for other calls.
+func (a *Analyzer) exitCall(call *ast.CallExpr) string {
+	if ident, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && a.isBuiltin(ident, "panic") {
+		return "panic"
+	}
+
+	switch name := a.calleeName(call); name {
+	case "os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln", "log.Logger.Fatal", "log.Logger.Fatalf", "log.Logger.Fatalln":
+		return name
+	}
+	return ""
+}
+
+// isBuiltin reports whether ident refers to the builtin function name.
+func (a *Analyzer) isBuiltin(ident *ast.Ident, name string) bool {
+	if ident.Name != name {
+		return false
+	}
+	if a.info != nil {
+		if obj := a.info.Uses[ident]; obj != nil {
+			_, ok := obj.(*types.Builtin)
+			return ok
+		}
+	}
+	return ident.Obj == nil
+}
+
+// funcIndex holds the functions declared in a file, keyed by their
+// types.Object when type information is available, otherwise by name, or
+// Type.Method for methods.
+type funcIndex struct {
+	a          *Analyzer
+	decls      map[any]*ast.FuncDecl
+	recoverers map[any]bool
+	// declared holds the identifiers naming the declarations
+	declared map[*ast.Ident]bool
+	// Without type information, methods holds the keys of the methods of
+	// each name and selectors the selector expressions by their selected
+	// identifier
+	methods   map[string][]any
+	selectors map[*ast.Ident]*ast.SelectorExpr
+}
+
+func (a *Analyzer) indexFuncs(file *ast.File) *funcIndex {
+	funcs := &funcIndex{
+		a:          a,
+		decls:      make(map[any]*ast.FuncDecl),
+		recoverers: make(map[any]bool),
+		declared:   make(map[*ast.Ident]bool),
+		methods:    make(map[string][]any),
+		selectors:  make(map[*ast.Ident]*ast.SelectorExpr),
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		key := a.declKey(fn)
+		funcs.decls[key] = fn
+		funcs.declared[fn.Name] = true
+		if a.callsRecover(fn.Body) {
+			funcs.recoverers[key] = true
+		}
+		if fn.Recv != nil {
+			funcs.methods[fn.Name.Name] = append(funcs.methods[fn.Name.Name], key)
+		}
+	}
+	if a.info == nil {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				funcs.selectors[sel.Sel] = sel
+			}
+			return true
+		})
+	}
+	return funcs
+}
+
+// declKey identifies a declared function: its types.Object when type
+// information is available, otherwise its name, or Type.Method for a
+// method.
+func (a *Analyzer) declKey(fn *ast.FuncDecl) any {
+	if a.info != nil {
+		if obj := a.info.Defs[fn.Name]; obj != nil {
+			return obj
+		}
+	}
+	return funcName(fn)
+}
+
+// refKeys returns the keys of the declared functions an identifier may
+// refer to. Without type information a method is selected from a value of
+// the type its declaration names, if the value is a declared variable or
+// parameter; otherwise every method of that name is returned.
+func (f *funcIndex) refKeys(ident *ast.Ident) []any {
+	if f.a.info != nil {
+		if obj := f.a.info.ObjectOf(ident); obj != nil {
+			if f.decls[obj] != nil {
+				return []any{obj}
+			}
+			return nil
+		}
+	}
+	if sel := f.selectors[ident]; sel != nil {
+		if typeName := declaredTypeName(sel.X); typeName != "" {
+			if key := typeName + "." + ident.Name; f.decls[key] != nil {
+				return []any{key}
+			}
+		}
+		return f.methods[ident.Name]
+	}
+	if f.decls[ident.Name] != nil {
+		return []any{ident.Name}
+	}
+	return nil
+}
+
+// declaredTypeName returns the name of the type expr is declared with when
+// it names a variable or parameter declared with an explicit type, or "".
+func declaredTypeName(expr ast.Expr) string {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok || ident.Obj == nil {
+		return ""
+	}
+
+	var typ ast.Expr
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.Field:
+		typ = decl.Type
+	case *ast.ValueSpec:
+		typ = decl.Type
+	}
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		typ = t.X
+	case *ast.IndexListExpr:
+		typ = t.X
+	}
+	if name, ok := typ.(*ast.Ident); ok {
+		return name.Name
+	}
+	return ""
+}
+
+// recovers reports whether ident refers to a declared function that calls
+// recover; without type information every method it may denote must.
+func (f *funcIndex) recovers(ident *ast.Ident) bool {
+	keys := f.refKeys(ident)
+	for _, key := range keys {
+		if !f.recoverers[key] {
+			return false
+		}
+	}
+	return len(keys) > 0
+}
+
+// calleeIdent returns the identifier naming a call's function or method.
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	}
+	return nil
+}
+
+// funcName returns a function's name, or Type.Method for a method.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
@@ -0,0 +1,309 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzePanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		typed    bool
+		allow    []string
+		code     string
+		want     []string
+	}{
+		{
+			name: "library exits",
+			code: `package store
+
+import (
+	"log"
+	"os"
+)
+
+func Open(path string) {
+	if path == "" {
+		panic("empty path")
+	}
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("stat: %v", err)
+	}
+	os.Exit(1)
+}
+`,
+			want: []string{
+				"panic in Open escapes package store; return an error instead",
+				"log.Fatalf ends the process outside package main; return an error instead",
+				"os.Exit ends the process outside package main; return an error instead",
+			},
+		},
+		{
+			name: "main package",
+			code: `package main
+
+import "os"
+
+func main() {
+	os.Exit(run())
+}
+`,
+		},
+		{
+			name: "init function",
+			code: `package store
+
+func init() {
+	if !valid() {
+		panic("invalid defaults")
+	}
+}
+`,
+		},
+		{
+			name:     "test file",
+			filename: "store_test.go",
+			code: `package store
+
+func mustLoad() {
+	panic("fixture missing")
+}
+`,
+		},
+		{
+			name:  "Must constructors",
+			allow: DefaultPanicAllowlist,
+			code: `package store
+
+func MustOpen(path string) *Store {
+	s, err := Open(path)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *Store) MustGet(key string) string {
+	panic("not found")
+}
+`,
+		},
+		{
+			name:  "allowlisted method",
+			allow: []string{"Store.Close"},
+			code: `package store
+
+func (s *Store) Close() {
+	panic("closed twice")
+}
+
+func (s *Store) Flush() {
+	panic("not open")
+}
+`,
+			want: []string{"panic in Store.Flush escapes package store; return an error instead"},
+		},
+		{
+			name: "recovered in the same function",
+			code: `package parse
+
+func Parse(src string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse: %v", r)
+		}
+	}()
+	if src == "" {
+		panic("empty")
+	}
+	return nil
+}
+`,
+		},
+		{
+			name:  "recovered by the callers",
+			typed: true,
+			code: `package parse
+
+import "fmt"
+
+type parser struct{ pos int }
+
+func (p *parser) fail(msg string) {
+	panic(fmt.Sprintf("%d: %s", p.pos, msg))
+}
+
+func (p *parser) expr() {
+	p.fail("unexpected token")
+}
+
+func (p *parser) recoverErr(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%v", r)
+	}
+}
+
+func Parse(src string) (err error) {
+	p := &parser{}
+	defer p.recoverErr(&err)
+	p.expr()
+	return nil
+}
+`,
+		},
+		{
+			name: "called without a recover",
+			code: `package parse
+
+func fail() {
+	panic("bad input")
+}
+
+func Parse(src string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errFailed
+		}
+	}()
+	fail()
+	return nil
+}
+
+func Check(src string) {
+	fail()
+}
+`,
+			want: []string{"panic in fail escapes package parse; return an error instead"},
+		},
+		{
+			name: "goroutines and function values",
+			code: `package parse
+
+func worker() {
+	panic("crash")
+}
+
+func Run(handlers map[string]func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errFailed
+			panic(r)
+		}
+	}()
+	go func() {
+		panic("detached")
+	}()
+	handlers["x"] = func() {
+		panic("later")
+	}
+	handlers["y"] = worker
+	return nil
+}
+`,
+			want: []string{
+				"panic in worker escapes package parse; return an error instead",
+				"panic in Run escapes package parse; return an error instead",
+				"panic in Run escapes package parse; return an error instead",
+				"panic in Run escapes package parse; return an error instead",
+			},
+		},
+		{
+			name:  "methods of the same name, typed",
+			typed: true,
+			code: `package pool
+
+type conn struct{}
+
+type file struct{}
+
+func (c *conn) close() {
+	panic("conn closed twice")
+}
+
+func (f *file) close() {
+	panic("file closed twice")
+}
+
+func Release(c *conn) {
+	defer func() {
+		recover()
+	}()
+	c.close()
+}
+
+func Remove(f *file) {
+	f.close()
+}
+`,
+			want: []string{
+				"panic in file.close escapes package pool; return an error instead",
+			},
+		},
+		{
+			name: "methods of the same name",
+			code: `package pool
+
+type conn struct{}
+
+type file struct{}
+
+func (c *conn) close() {
+	panic("conn closed twice")
+}
+
+func (f *file) close() {
+	panic("file closed twice")
+}
+
+func Release(c *conn) {
+	defer func() {
+		recover()
+	}()
+	c.close()
+}
+
+func Remove(f *file) {
+	f.close()
+}
+`,
+			want: []string{
+				"panic in file.close escapes package pool; return an error instead",
+			},
+		},
+		{
+			name:  "shadowed panic",
+			typed: true,
+			code: `package parse
+
+func panic(msg string) {}
+
+func Parse() {
+	panic("not the builtin")
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := tt.filename
+			if filename == "" {
+				filename = "test.go"
+			}
+			analyzer := NewAnalyzer(AnalyzerConfig{TypeCheck: tt.typed})
+			file, err := analyzer.ParseString(filename, tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			var got []string
+			for _, issue := range analyzer.AnalyzePanics(file, tt.allow) {
+				got = append(got, issue.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// SuppressionDirective starts a comment that silences findings:
+//
+//	//mcplsp:ignore <rule-id>[,<rule-id>...] <reason>
+const SuppressionDirective = "//mcplsp:ignore"
+
+// Scopes a suppression can apply to.
+const (
+	ScopeLine     = "line"
+	ScopeBlock    = "block"
+	ScopeFunction = "function"
+	ScopeFile     = "file"
+)
+
+// Suppression is a //mcplsp:ignore directive. A directive at the end of a
+// line covers that line. A directive on a line of its own covers the
+// declaration or statement that follows it, including its body, so that
+// above a function it covers the function and above an if, for, switch or
+// block statement it covers the block. A directive above the package clause
+// covers the file.
+type Suppression struct {
+	RuleIDs  []string
+	Reason   string
+	Scope    string
+	Position token.Position
+	// StartLine and EndLine are the lines the directive covers
+	StartLine int
+	EndLine   int
+	// Used records whether the directive silenced a finding
+	Used bool
+}
+
+// Valid reports whether the directive names at least one rule and gives a
+// reason. Invalid directives silence nothing.
+func (s *Suppression) Valid() bool {
+	return len(s.RuleIDs) > 0 && s.Reason != ""
+}
+
+// Covers reports whether the directive applies to a finding on the given
+// line of its file.
+func (s *Suppression) Covers(filename string, line int) bool {
+	return filename == s.Position.Filename && line >= s.StartLine && line <= s.EndLine
+}
+
+// Suppressions returns the //mcplsp:ignore directives in file.
+func (a *Analyzer) Suppressions(file *ast.File) []*Suppression {
+	var suppressions []*Suppression
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if comment.Text != SuppressionDirective && !strings.HasPrefix(comment.Text, SuppressionDirective+" ") {
+				continue
+			}
+
+			fields := strings.Fields(strings.TrimPrefix(comment.Text, SuppressionDirective))
+			s := &Suppression{Position: a.fset.Position(comment.Pos())}
+			if len(fields) > 0 {
+				for _, id := range strings.Split(fields[0], ",") {
+					if id != "" {
+						s.RuleIDs = append(s.RuleIDs, id)
+					}
+				}
+				s.Reason = strings.Join(fields[1:], " ")
+			}
+			a.scope(file, group, comment, s)
+			suppressions = append(suppressions, s)
+		}
+	}
+	return suppressions
+}
+
+// scope works out the lines a directive covers.
+func (a *Analyzer) scope(file *ast.File, group *ast.CommentGroup, comment *ast.Comment, s *Suppression) {
+	line := s.Position.Line
+	s.Scope, s.StartLine, s.EndLine = ScopeLine, line, line
+
+	if group.End() < file.Package {
+		s.Scope, s.StartLine, s.EndLine = ScopeFile, 1, a.fset.File(file.Pos()).LineCount()
+		return
+	}
+
+	// A directive sharing its line with code covers that line only
+	if a.codeOnLine(file, line, comment) {
+		return
+	}
+
+	// Otherwise it covers the outermost node starting on the line after
+	// its comment group
+	next := a.fset.Position(group.End()).Line + 1
+	var target ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.CommentGroup, *ast.Comment:
+			return false
+		case *ast.File:
+			return true
+		}
+		if a.fset.Position(n.Pos()).Line == next {
+			if target == nil || n.End() > target.End() {
+				target = n
+			}
+			return false
+		}
+		return a.fset.Position(n.Pos()).Line < next && a.fset.Position(n.End()).Line >= next
+	})
+	if target == nil {
+		return
+	}
+
+	s.StartLine, s.EndLine = line, a.fset.Position(target.End()).Line
+	if _, ok := target.(*ast.FuncDecl); ok {
+		s.Scope = ScopeFunction
+	} else if s.EndLine > next {
+		s.Scope = ScopeBlock
+	}
+}
+
+// codeOnLine reports whether code precedes the comment on its line.
+func (a *Analyzer) codeOnLine(file *ast.File, line int, comment *ast.Comment) bool {
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found || n == nil {
+			return false
+		}
+		switch n.(type) {
+		case *ast.File:
+			return true
+		case *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		start, end := a.fset.Position(n.Pos()), a.fset.Position(n.End())
+		if start.Line > line || end.Line < line {
+			return false
+		}
+		if (start.Line == line && n.Pos() < comment.Pos()) || (end.Line == line && n.End() <= comment.Pos()) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,155 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuppressions(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		rules  []string
+		reason string
+		scope  string
+		start  int
+		end    int
+	}{
+		{
+			name: "trailing comment",
+			code: `package test
+
+var ErrNotFound = errors.New("not found") //mcplsp:ignore org_coding_standards sentinel error
+`,
+			rules:  []string{"org_coding_standards"},
+			reason: "sentinel error",
+			scope:  ScopeLine,
+			start:  3,
+			end:    3,
+		},
+		{
+			name: "statement",
+			code: `package test
+
+func f() {
+	//mcplsp:ignore error_handling best effort
+	os.Remove(path)
+	close()
+}
+`,
+			rules:  []string{"error_handling"},
+			reason: "best effort",
+			scope:  ScopeLine,
+			start:  4,
+			end:    5,
+		},
+		{
+			name: "block",
+			code: `package test
+
+func f() {
+	//mcplsp:ignore error_handling,secure_coding cleanup only
+	if done {
+		os.Remove(path)
+	}
+	close()
+}
+`,
+			rules:  []string{"error_handling", "secure_coding"},
+			reason: "cleanup only",
+			scope:  ScopeBlock,
+			start:  4,
+			end:    7,
+		},
+		{
+			name: "function",
+			code: `package test
+
+// do_work is called from generated code.
+//
+//mcplsp:ignore org_coding_standards name is fixed by the generator
+func do_work() {
+	run()
+}
+`,
+			rules:  []string{"org_coding_standards"},
+			reason: "name is fixed by the generator",
+			scope:  ScopeFunction,
+			start:  5,
+			end:    8,
+		},
+		{
+			name: "file",
+			code: `//mcplsp:ignore api_design legacy handlers
+
+package test
+
+func Handle(id string) error {
+	return nil
+}
+`,
+			rules:  []string{"api_design"},
+			reason: "legacy handlers",
+			scope:  ScopeFile,
+			start:  1,
+			end:    7,
+		},
+		{
+			name: "missing reason",
+			code: `package test
+
+var ErrNotFound = errors.New("not found") //mcplsp:ignore org_coding_standards
+`,
+			rules: []string{"org_coding_standards"},
+			scope: ScopeLine,
+			start: 3,
+			end:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			suppressions := analyzer.Suppressions(file)
+			if len(suppressions) != 1 {
+				t.Fatalf("Expected 1 suppression, got %d", len(suppressions))
+			}
+			s := suppressions[0]
+
+			if !reflect.DeepEqual(s.RuleIDs, tt.rules) {
+				t.Errorf("Expected rules %v, got %v", tt.rules, s.RuleIDs)
+			}
+			if s.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, s.Reason)
+			}
+			if s.Valid() != (tt.reason != "") {
+				t.Errorf("Expected valid %v, got %v", tt.reason != "", s.Valid())
+			}
+			if s.Scope != tt.scope || s.StartLine != tt.start || s.EndLine != tt.end {
+				t.Errorf("Expected %s scope over lines %d-%d, got %s over %d-%d", tt.scope, tt.start, tt.end, s.Scope, s.StartLine, s.EndLine)
+			}
+		})
+	}
+}
+
+func TestSuppressionsIgnoreOtherComments(t *testing.T) {
+	analyzer := NewAnalyzer(AnalyzerConfig{})
+	file, err := analyzer.ParseString("test.go", `package test
+
+//mcplsp:ignored is not a directive
+// mcplsp:ignore neither is this
+func f() {}
+`)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	if suppressions := analyzer.Suppressions(file); len(suppressions) != 0 {
+		t.Errorf("Expected no suppressions, got %+v", suppressions)
+	}
+}
@@ -0,0 +1,202 @@
+package ast
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+)
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// TypeCheck type-checks the files as a single package and records the
+// results so that the analyses can ask about expression types instead of
+// relying on identifier names. Type errors, such as unresolvable imports, do
+// not stop checking; they are returned so callers can surface them, and the
+// affected expressions fall back to the syntactic heuristics.
+func (a *Analyzer) TypeCheck(files ...*ast.File) []error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	if a.info == nil {
+		a.info = &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		}
+	}
+	if a.importer == nil {
+		a.importer = importer.ForCompiler(a.fset, "source", nil)
+	}
+
+	var errs []error
+	conf := types.Config{
+		Importer: a.importer,
+		Error: func(err error) {
+			errs = append(errs, err)
+		},
+	}
+
+	conf.Check(files[0].Name.Name, a.fset, files, a.info)
+
+	return errs
+}
+
+// Fork returns an analyzer with the same configuration and importer but a
+// file set of its own and none of the files or type information recorded so
+// far, so that a long-lived analyzer can run one analysis after another
+// without accumulating files or type-checking imported packages again. As
+// they share the importer, a and its forks must not type-check concurrently.
+func (a *Analyzer) Fork() *Analyzer {
+	if a.config.TypeCheck && a.importer == nil {
+		a.importer = importer.ForCompiler(a.fset, "source", nil)
+	}
+	return &Analyzer{
+		config:   a.config,
+		fset:     token.NewFileSet(),
+		importer: a.importer,
+		sources:  make(map[string][]byte),
+	}
+}
+
+// HasTypeInfo reports whether type information has been recorded.
+func (a *Analyzer) HasTypeInfo() bool {
+	return a.info != nil
+}
+
+// TypeOf returns the type of an expression, or nil when it is unknown.
+func (a *Analyzer) TypeOf(expr ast.Expr) types.Type {
+	if a.info == nil {
+		return nil
+	}
+	t := a.info.TypeOf(expr)
+	if t == nil || t == types.Typ[types.Invalid] {
+		return nil
+	}
+	return t
+}
+
+// IsError reports whether expr has type error. Without type information it
+// falls back to treating identifiers named err as errors.
+func (a *Analyzer) IsError(expr ast.Expr) bool {
+	if t := a.TypeOf(expr); t != nil {
+		return types.Identical(t, errorType)
+	}
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "err"
+}
+
+// IsMutex reports whether expr is a sync.Mutex or sync.RWMutex, or a pointer
+// to one. Without type information it falls back to sync.Mutex selectors and
+// conventional lock variable names.
+func (a *Analyzer) IsMutex(expr ast.Expr) bool {
+	if t := a.TypeOf(expr); t != nil {
+		return isNamed(t, "sync", "Mutex") || isNamed(t, "sync", "RWMutex")
+	}
+
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return a.IsMutex(e.X)
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "sync" {
+			return e.Sel.Name == "Mutex" || e.Sel.Name == "RWMutex"
+		}
+		return isLockName(e.Sel.Name)
+	case *ast.Ident:
+		return isLockName(e.Name)
+	}
+	return false
+}
+
+// IsWaitGroup reports whether expr is a sync.WaitGroup or a pointer to one.
+// Without type information it falls back to conventional WaitGroup names.
+func (a *Analyzer) IsWaitGroup(expr ast.Expr) bool {
+	if t := a.TypeOf(expr); t != nil {
+		return isNamed(t, "sync", "WaitGroup")
+	}
+
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return a.IsWaitGroup(e.X)
+	case *ast.SelectorExpr:
+		return isWaitGroupName(e.Sel.Name)
+	case *ast.Ident:
+		return isWaitGroupName(e.Name)
+	}
+	return false
+}
+
+// IsContext reports whether expr is a context.Context. Without type
+// information it only recognizes the selector context.Context.
+func (a *Analyzer) IsContext(expr ast.Expr) bool {
+	if t := a.TypeOf(expr); t != nil {
+		return isNamed(t, "context", "Context")
+	}
+
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return ident.Name == "context" && sel.Sel.Name == "Context"
+		}
+	}
+	return false
+}
+
+// IsMap reports whether expr has a map type. It returns false when type
+// information is unavailable.
+func (a *Analyzer) IsMap(expr ast.Expr) bool {
+	t := a.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Map)
+	return ok
+}
+
+// ResultTypes returns the result types of a call, or nil when the callee's
+// signature is unknown.
+func (a *Analyzer) ResultTypes(call *ast.CallExpr) []types.Type {
+	t := a.TypeOf(call.Fun)
+	if t == nil {
+		return nil
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok {
+		return nil
+	}
+
+	results := make([]types.Type, sig.Results().Len())
+	for i := range results {
+		results[i] = sig.Results().At(i).Type()
+	}
+	return results
+}
+
+func isNamed(t types.Type, pkgPath, name string) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+func isLockName(name string) bool {
+	switch name {
+	case "mu", "mutex", "rwmu", "rwmutex", "lock":
+		return true
+	}
+	return false
+}
+
+func isWaitGroupName(name string) bool {
+	switch name {
+	case "wg", "waitGroup", "waitgroup":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,401 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AnalyzeErrorWrapping enforces how errors are wrapped and inspected:
+//
+//   - an error returned by another package's function is returned on
+//     without being wrapped with context
+//   - fmt.Errorf formats an error with a verb other than %w, which breaks
+//     errors.Is and errors.As
+//   - an error already wrapped with %w is wrapped again, on some path
+//     through its function or directly inside another fmt.Errorf
+//   - err.Error() is compared as a string
+//   - an error is compared to a sentinel such as io.EOF with == or != or a
+//     switch case, which misses wrapped errors
+func (a *Analyzer) AnalyzeErrorWrapping(file *ast.File) []Issue {
+	var issues []Issue
+	report := func(node ast.Node, description string, fixes []SuggestedFix) {
+		issues = append(issues, Issue{
+			RuleID:         "error_wrapping",
+			Description:    description,
+			Severity:       "warning",
+			Position:       a.GetPositionOf(node),
+			SuggestedFixes: fixes,
+		})
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			// errors.Is calls an Is method to compare with ==
+			if node.Name.Name == "Is" && node.Recv != nil {
+				return false
+			}
+			if node.Body != nil {
+				a.checkPropagation(file, node.Body, report)
+			}
+		case *ast.FuncLit:
+			a.checkPropagation(file, node.Body, report)
+		case *ast.CallExpr:
+			a.checkErrorf(file, node, report)
+			a.checkErrorStringCall(node, report)
+		case *ast.BinaryExpr:
+			a.checkComparison(file, node, report)
+		case *ast.SwitchStmt:
+			a.checkSentinelSwitch(node, report)
+		}
+		return true
+	})
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Position.Offset < issues[j].Position.Offset
+	})
+	return issues
+}
+
+type reportFunc func(node ast.Node, description string, fixes []SuggestedFix)
+
+// checkPropagation follows errors through a function body's control-flow
+// graph, reporting those from other packages that reach a return unwrapped
+// and wrapped errors that are wrapped again.
+func (a *Analyzer) checkPropagation(file *ast.File, body *ast.BlockStmt, report reportFunc) {
+	cfg := buildCFG(body, a.isNoReturn)
+	flow := &errorFlow{a: a}
+
+	reportedReturns := make(map[ast.Expr]bool)
+	reportedWraps := make(map[*ast.CallExpr]bool)
+	for _, block := range cfg.blocks {
+		for i, node := range block.nodes {
+			assign, ok := node.(*ast.AssignStmt)
+			if !ok || len(assign.Rhs) != 1 {
+				continue
+			}
+			call, ok := assign.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+
+			for _, lhs := range assign.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || ident.Name == "_" || !a.IsError(ident) {
+					continue
+				}
+				key := a.varKey(ident)
+				if key == nil {
+					continue
+				}
+
+				switch {
+				case a.wrapsWithW(call) != nil:
+					cfg.walk(block, i+1, func(n ast.Node) bool {
+						if wrap := a.wrapOf(n, key); wrap != nil && !reportedWraps[wrap] {
+							reportedWraps[wrap] = true
+							report(wrap, fmt.Sprintf("Error %s is wrapped again after already being wrapped with %%w", ident.Name), nil)
+							return true
+						}
+						return flow.writes(n, key)
+					})
+
+				case a.isExternalCall(file, call):
+					name := types.ExprString(call.Fun)
+					cfg.walk(block, i+1, func(n ast.Node) bool {
+						if ret, ok := n.(*ast.ReturnStmt); ok {
+							for _, result := range ret.Results {
+								if id, ok := result.(*ast.Ident); ok && a.varKey(id) == key && !reportedReturns[result] {
+									reportedReturns[result] = true
+									report(result, fmt.Sprintf("Error from %s is returned without context; wrap it with fmt.Errorf and %%w", name), a.fixer(file).wrapReturn(result, name))
+								}
+							}
+							return true
+						}
+						return flow.writes(n, key)
+					})
+				}
+			}
+		}
+	}
+}
+
+// wrapOf returns a call in node that wraps the variable with %w.
+func (a *Analyzer) wrapOf(node ast.Node, key any) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok {
+			for _, arg := range a.wrapsWithW(call) {
+				if ident, ok := arg.(*ast.Ident); ok && a.varKey(ident) == key {
+					found = call
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// checkErrorf reports fmt.Errorf calls that format an error with a verb
+// other than %w, and that wrap the result of another fmt.Errorf.
+func (a *Analyzer) checkErrorf(file *ast.File, call *ast.CallExpr, report reportFunc) {
+	if !a.isErrorf(call) || len(call.Args) == 0 {
+		return
+	}
+	verbs, ok := formatVerbs(call.Args[0])
+	if !ok {
+		return
+	}
+
+	for i, verb := range verbs {
+		if i+1 >= len(call.Args) {
+			break
+		}
+		arg := call.Args[i+1]
+
+		if verb.verb == 'w' {
+			if inner, ok := ast.Unparen(arg).(*ast.CallExpr); ok && a.wrapsWithW(inner) != nil {
+				report(call, "fmt.Errorf wraps an error just wrapped by fmt.Errorf; add the context in one call", nil)
+			}
+			continue
+		}
+		if verb.verb != 'v' && verb.verb != 's' {
+			continue
+		}
+
+		// err.Error() formatted with %s loses the chain just the same
+		errExpr := arg
+		if x, ok := errorString(arg); ok {
+			errExpr = x
+		}
+		if !a.IsError(errExpr) {
+			continue
+		}
+		report(arg, fmt.Sprintf("fmt.Errorf formats error %s with %%%c; use %%w so it can be inspected with errors.Is and errors.As", types.ExprString(errExpr), verb.verb),
+			a.fixer(file).useVerbW(call.Args[0].(*ast.BasicLit), verb.offset, arg, errExpr))
+	}
+}
+
+// checkErrorStringCall reports err.Error() passed to strings.Contains and
+// similar matching functions.
+func (a *Analyzer) checkErrorStringCall(call *ast.CallExpr, report reportFunc) {
+	switch a.getFunctionName(call) {
+	case "strings.Contains", "strings.HasPrefix", "strings.HasSuffix", "strings.EqualFold":
+	default:
+		return
+	}
+	for _, arg := range call.Args {
+		if x, ok := errorString(arg); ok && a.IsError(x) {
+			report(call, "Error matched by its Error() text; use errors.Is or errors.As", nil)
+			return
+		}
+	}
+}
+
+// checkComparison reports err.Error() string comparisons and comparisons
+// of an error with a sentinel using == or !=.
+func (a *Analyzer) checkComparison(file *ast.File, expr *ast.BinaryExpr, report reportFunc) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+
+	for _, side := range []ast.Expr{expr.X, expr.Y} {
+		if x, ok := errorString(side); ok && a.IsError(x) {
+			report(expr, "Error compared by its Error() text; use errors.Is or errors.As", nil)
+			return
+		}
+	}
+
+	err, sentinel := expr.X, expr.Y
+	if a.isSentinel(err) {
+		err, sentinel = sentinel, err
+	}
+	if isNil(err) || !a.isSentinel(sentinel) || !a.IsError(err) && !a.isSentinel(err) {
+		return
+	}
+	report(expr, fmt.Sprintf("Error compared to sentinel %s with %s; use errors.Is", types.ExprString(sentinel), expr.Op),
+		a.fixer(file).useErrorsIs(expr, err, sentinel))
+}
+
+// checkSentinelSwitch reports switch statements on an error whose cases
+// are sentinels.
+func (a *Analyzer) checkSentinelSwitch(stmt *ast.SwitchStmt, report reportFunc) {
+	if stmt.Tag == nil || !a.IsError(stmt.Tag) {
+		return
+	}
+	for _, c := range stmt.Body.List {
+		for _, value := range c.(*ast.CaseClause).List {
+			if a.isSentinel(value) {
+				report(value, fmt.Sprintf("Error compared to sentinel %s by a switch case; use errors.Is in a tagless switch", types.ExprString(value)), nil)
+			}
+		}
+	}
+}
+
+// isSentinel reports whether expr refers to a package-level error variable.
+// Without type information it recognizes names starting with Err and io.EOF.
+func (a *Analyzer) isSentinel(expr ast.Expr) bool {
+	var ident *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return false
+	}
+
+	if a.info != nil {
+		if v, ok := a.info.Uses[ident].(*types.Var); ok && v.Pkg() != nil {
+			return v.Parent() == v.Pkg().Scope() && types.Implements(v.Type(), errorType.Underlying().(*types.Interface))
+		}
+	}
+	if ident.Obj != nil && ident.Obj.Kind == ast.Var && ident.Obj.Decl != nil {
+		// A local variable, or one the parser resolved in this file
+		if _, ok := ident.Obj.Decl.(*ast.ValueSpec); !ok {
+			return false
+		}
+	}
+	return strings.HasPrefix(ident.Name, "Err") || ident.Name == "EOF"
+}
+
+// isErrorf reports whether call is fmt.Errorf.
+func (a *Analyzer) isErrorf(call *ast.CallExpr) bool {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && a.info != nil {
+		if fn, ok := a.info.Uses[sel.Sel].(*types.Func); ok {
+			return fn.Pkg() != nil && fn.Pkg().Path() == "fmt" && fn.Name() == "Errorf"
+		}
+	}
+	return a.getFunctionName(call) == "fmt.Errorf"
+}
+
+// wrapsWithW returns the arguments a fmt.Errorf call wraps with %w, or nil
+// for other calls.
+func (a *Analyzer) wrapsWithW(call *ast.CallExpr) []ast.Expr {
+	if !a.isErrorf(call) || len(call.Args) == 0 {
+		return nil
+	}
+	verbs, ok := formatVerbs(call.Args[0])
+	if !ok {
+		return nil
+	}
+
+	var wrapped []ast.Expr
+	for i, verb := range verbs {
+		if verb.verb == 'w' && i+1 < len(call.Args) {
+			wrapped = append(wrapped, call.Args[i+1])
+		}
+	}
+	return wrapped
+}
+
+// isExternalCall reports whether call invokes a function or method of
+// another package, other than fmt and errors whose errors carry their own
+// context. Without type information only calls qualified with an imported
+// package name are recognized.
+func (a *Analyzer) isExternalCall(file *ast.File, call *ast.CallExpr) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	if a.info != nil {
+		if fn, ok := a.info.Uses[sel.Sel].(*types.Func); ok {
+			if fn.Pkg() == nil {
+				return false
+			}
+			path := fn.Pkg().Path()
+			// The checked package is named after its package clause
+			return path != file.Name.Name && path != "fmt" && path != "errors"
+		}
+	}
+
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || x.Obj != nil || x.Name == "fmt" || x.Name == "errors" {
+		return false
+	}
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == x.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// errorString returns x for an expression x.Error().
+func errorString(expr ast.Expr) (ast.Expr, bool) {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Error" {
+		return nil, false
+	}
+	return sel.X, true
+}
+
+// formatVerb is a verb of a format string and the byte offset of its verb
+// character in the unquoted string.
+type formatVerb struct {
+	verb   rune
+	offset int
+}
+
+// formatVerbs returns the verbs of a constant format string, one per
+// argument consumed; a * width or precision consumes an argument as the
+// verb '*'. It returns false for non-literal formats and for formats using
+// explicit argument indexes.
+func formatVerbs(expr ast.Expr) ([]formatVerb, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil, false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return nil, false
+	}
+
+	var verbs []formatVerb
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				return nil, false
+			}
+			if c == '*' {
+				verbs = append(verbs, formatVerb{'*', i})
+				continue
+			}
+			if strings.IndexByte("+-# 0.123456789", c) < 0 {
+				break
+			}
+		}
+		if i < len(format) && format[i] != '%' {
+			verbs = append(verbs, formatVerb{rune(format[i]), i})
+		}
+	}
+	return verbs, true
+}
@@ -0,0 +1,219 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeErrorWrapping(t *testing.T) {
+	tests := []struct {
+		name  string
+		typed bool
+		code  string
+		want  []string
+	}{
+		{
+			name: "returned from another package",
+			code: `package test
+
+import "os"
+
+func load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+`,
+			want: []string{"Error from os.Open is returned without context; wrap it with fmt.Errorf and %w"},
+		},
+		{
+			name: "returned from the same package",
+			code: `package test
+
+func load(path string) error {
+	err := open(path)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+		},
+		{
+			name: "reassigned before the return",
+			code: `package test
+
+import "os"
+
+func load(path string) error {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		err = create(path)
+	}
+	return err
+}
+`,
+			want: []string{"Error from os.Stat is returned without context; wrap it with fmt.Errorf and %w"},
+		},
+		{
+			name: "wrapped before the return",
+			code: `package test
+
+import (
+	"fmt"
+	"os"
+)
+
+func load(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return nil
+}
+`,
+		},
+		{
+			name:  "formatted with %v and %s",
+			typed: true,
+			code: `package test
+
+import "fmt"
+
+func wrap(err error) (error, error, error) {
+	return fmt.Errorf("a: %v", err), fmt.Errorf("b %*d: %s", 3, 1, err.Error()), fmt.Errorf("c %d%%: %v", 1, "text")
+}
+`,
+			want: []string{
+				"fmt.Errorf formats error err with %v; use %w so it can be inspected with errors.Is and errors.As",
+				"fmt.Errorf formats error err with %s; use %w so it can be inspected with errors.Is and errors.As",
+			},
+		},
+		{
+			name: "wrapped twice",
+			code: `package test
+
+import "fmt"
+
+func wrap(err error) error {
+	err = fmt.Errorf("inner: %w", err)
+	return fmt.Errorf("outer: %w", err)
+}
+
+func nested(err error) error {
+	return fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", err))
+}
+`,
+			want: []string{
+				"Error err is wrapped again after already being wrapped with %w",
+				"fmt.Errorf wraps an error just wrapped by fmt.Errorf; add the context in one call",
+			},
+		},
+		{
+			name: "wrapped on separate paths",
+			code: `package test
+
+import "fmt"
+
+func wrap(err error, retry bool) error {
+	if retry {
+		return fmt.Errorf("retry: %w", err)
+	}
+	return fmt.Errorf("fail: %w", err)
+}
+`,
+		},
+		{
+			name: "error text compared",
+			code: `package test
+
+import "strings"
+
+func match(err error) bool {
+	return err.Error() == "not found" || strings.Contains(err.Error(), "timeout")
+}
+`,
+			want: []string{
+				"Error compared by its Error() text; use errors.Is or errors.As",
+				"Error matched by its Error() text; use errors.Is or errors.As",
+			},
+		},
+		{
+			name:  "sentinel compared",
+			typed: true,
+			code: `package test
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrClosed = errors.New("closed")
+
+func done(err error) bool {
+	if err == nil || err == io.EOF {
+		return true
+	}
+	switch err {
+	case ErrClosed:
+		return true
+	}
+	return ErrClosed != err
+}
+`,
+			want: []string{
+				"Error compared to sentinel io.EOF with ==; use errors.Is",
+				"Error compared to sentinel ErrClosed by a switch case; use errors.Is in a tagless switch",
+				"Error compared to sentinel ErrClosed with !=; use errors.Is",
+			},
+		},
+		{
+			name: "sentinel compared without types",
+			code: `package test
+
+import "io"
+
+func done(err error) bool {
+	errLocal := read()
+	return err == io.EOF || err == errLocal
+}
+`,
+			want: []string{"Error compared to sentinel io.EOF with ==; use errors.Is"},
+		},
+		{
+			name:  "Is method",
+			typed: true,
+			code: `package test
+
+import "errors"
+
+var ErrClosed = errors.New("closed")
+
+type closedError struct{}
+
+func (closedError) Error() string { return "closed" }
+
+func (closedError) Is(target error) bool { return target == ErrClosed }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(AnalyzerConfig{TypeCheck: tt.typed})
+			file, err := analyzer.ParseString("test.go", tt.code)
+			if err != nil {
+				t.Fatalf("Failed to parse code: %v", err)
+			}
+
+			var got []string
+			for _, issue := range analyzer.AnalyzeErrorWrapping(file) {
+				got = append(got, issue.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
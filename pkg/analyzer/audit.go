@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+// DriftKind classifies a mismatch between the YAML rules and the registered
+// analyses.
+type DriftKind string
+
+const (
+	// DriftUnenforcedRule is a YAML rule without a registered analysis. Its
+	// pattern checks, if any, are all that enforce it.
+	DriftUnenforcedRule DriftKind = "unenforced_rule"
+	// DriftOrphanAnalysis is a registered analysis without a YAML rule. The
+	// engine still runs it when asked for its id, but no category, alias or
+	// rule file selects it and its findings carry no rule documentation.
+	DriftOrphanAnalysis DriftKind = "orphan_analysis"
+	// DriftSeverityMismatch is a rule whose YAML severity differs from the
+	// severity its analysis reports findings with.
+	DriftSeverityMismatch DriftKind = "severity_mismatch"
+)
+
+// Drift is a single audit finding.
+type Drift struct {
+	Kind             DriftKind `json:"kind"`
+	RuleID           string    `json:"ruleId"`
+	Path             string    `json:"path,omitempty"`
+	RuleSeverity     string    `json:"ruleSeverity,omitempty"`
+	AnalyzerSeverity string    `json:"analyzerSeverity,omitempty"`
+	Message          string    `json:"message"`
+}
+
+// AuditReport is the result of comparing the YAML rules with the registered
+// analyses.
+type AuditReport struct {
+	Rules    int     `json:"rules"`
+	Analyses int     `json:"analyses"`
+	Drift    []Drift `json:"drift"`
+}
+
+// Audit compares every rule in the registry with the registered analyses and
+// reports rules without an analysis, analyses without a rule and severity
+// mismatches between the two. An analysis reports every finding with the
+// severity of its RuleMeta.
+func Audit(registry *rules.RuleRegistry) *AuditReport {
+	analyses := Rules()
+	report := &AuditReport{
+		Rules:    len(registry.IDs()),
+		Analyses: len(analyses),
+		Drift:    []Drift{},
+	}
+
+	for _, rule := range registry.Rules() {
+		path, _ := registry.Path(rule.ID)
+
+		analysis, ok := Lookup(rule.ID)
+		if !ok {
+			msg := "no registered analysis and no pattern checks; the rule is not enforced"
+			if n := len(rule.Checks); n > 0 {
+				msg = fmt.Sprintf("no registered analysis; only enforced by %d pattern check(s)", n)
+			}
+			report.Drift = append(report.Drift, Drift{
+				Kind:    DriftUnenforcedRule,
+				RuleID:  rule.ID,
+				Path:    path,
+				Message: msg,
+			})
+			continue
+		}
+
+		severity := analysis.Meta().Severity
+		if !strings.EqualFold(severity, rule.Severity) {
+			report.Drift = append(report.Drift, Drift{
+				Kind:             DriftSeverityMismatch,
+				RuleID:           rule.ID,
+				Path:             path,
+				RuleSeverity:     rule.Severity,
+				AnalyzerSeverity: severity,
+				Message:          fmt.Sprintf("rule severity is %s but the analysis reports %s", rule.Severity, severity),
+			})
+		}
+	}
+
+	for _, analysis := range analyses {
+		id := analysis.ID()
+		if rule, ok := registry.Lookup(id); ok {
+			if rule.ID == id {
+				continue
+			}
+			// Registered under an alias: the engine resolves the alias to
+			// the rule id and never finds the analysis.
+			report.Drift = append(report.Drift, Drift{
+				Kind:    DriftOrphanAnalysis,
+				RuleID:  id,
+				Message: fmt.Sprintf("analysis is registered under alias %s of rule %s instead of its id", id, rule.ID),
+			})
+			continue
+		}
+
+		report.Drift = append(report.Drift, Drift{
+			Kind:    DriftOrphanAnalysis,
+			RuleID:  id,
+			Message: "registered analysis has no YAML rule; it only runs when requested by id",
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func TestAudit(t *testing.T) {
+	if _, ok := Lookup("test_audit_alias"); !ok {
+		Register(NewRule("test_audit_alias", RuleMeta{Severity: "warning"}, func(*Pass) []ast.Issue {
+			return nil
+		}))
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		// Matches the builtin analysis, but disagrees on severity
+		"error_handling.yaml": `id: error_handling
+description: Errors must be handled
+severity: error
+checks:
+  - name: checked
+    pattern: "if err != nil"
+    ensure: present
+`,
+		"api_design.yaml": `id: api_design
+description: Context first
+severity: warning
+`,
+		"undocumented.yaml": `id: undocumented_rule
+aliases: [test_audit_alias]
+description: No analysis behind this one
+severity: info
+checks:
+  - name: todo
+    pattern: "TODO"
+    ensure: absent
+`,
+	})
+
+	registry, err := rules.NewRuleRegistry(dir)
+	if err != nil {
+		t.Fatalf("NewRuleRegistry: %v", err)
+	}
+
+	report := Audit(registry)
+	if report.Rules != 3 {
+		t.Errorf("expected 3 rules, got %d", report.Rules)
+	}
+
+	got := make(map[DriftKind][]string)
+	for _, d := range report.Drift {
+		got[d.Kind] = append(got[d.Kind], d.RuleID)
+	}
+
+	tests := []struct {
+		kind    DriftKind
+		ruleID  string
+		present bool
+	}{
+		{DriftSeverityMismatch, "error_handling", true},
+		{DriftSeverityMismatch, "api_design", false},
+		{DriftUnenforcedRule, "undocumented_rule", true},
+		{DriftOrphanAnalysis, "test_audit_alias", true},
+		{DriftOrphanAnalysis, "secure_coding", true},
+		{DriftOrphanAnalysis, "error_handling", false},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, id := range got[tt.kind] {
+			found = found || id == tt.ruleID
+		}
+		if found != tt.present {
+			t.Errorf("%s for %s: got present=%v, want %v", tt.kind, tt.ruleID, found, tt.present)
+		}
+	}
+}
+
+// The audit compares YAML severities with RuleMeta.Severity, so every
+// finding of a builtin analysis must carry it.
+func TestBuiltinFindingsUseMetaSeverity(t *testing.T) {
+	paths, err := filepath.Glob("../../testdata/*/*.go")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("No testdata files found: %v", err)
+	}
+
+	var ids []string
+	for _, rule := range Rules() {
+		if !strings.HasPrefix(rule.ID(), "test_") {
+			ids = append(ids, rule.ID())
+		}
+	}
+
+	engine, err := NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: true, IncludeTests: true}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := engine.Fork().Analyze(path, src, ids)
+		if err != nil {
+			t.Fatalf("Analysis of %s failed: %v", path, err)
+		}
+		for _, issue := range result.Issues {
+			found[issue.RuleID] = true
+			rule, _ := Lookup(issue.RuleID)
+			if want := rule.Meta().Severity; issue.Severity != want {
+				t.Errorf("%s: %s reported %q with severity %s, its meta says %s", issue.Position, issue.RuleID, issue.Description, issue.Severity, want)
+			}
+		}
+	}
+	if len(found) < 5 {
+		t.Errorf("Expected findings from most builtin analyses, got %v", found)
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+func init() {
+	Register(NewRule("error_handling", RuleMeta{
+		Description: "Errors must be checked after assignment and never discarded",
+		Category:    "code_quality",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		return pass.Analyzer.AnalyzeErrorHandling(pass.File)
+	}))
+
+	Register(NewRule("discarded_errors", RuleMeta{
+		Description: "Error results of calls must not be dropped, outside an allowlist",
+		Category:    "code_quality",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		allow := ast.DefaultDiscardAllowlist
+		if pass.Rule != nil && pass.Rule.Allow != nil {
+			allow = pass.Rule.Allow
+		}
+		return pass.Analyzer.AnalyzeDiscardedErrors(pass.File, allow)
+	}))
+
+	Register(NewRule("error_wrapping", RuleMeta{
+		Description: "Errors must be wrapped with %w and compared with errors.Is or errors.As",
+		Category:    "code_quality",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		return pass.Analyzer.AnalyzeErrorWrapping(pass.File)
+	}))
+
+	Register(NewRule("api_design", RuleMeta{
+		Description: "API methods take context.Context as their first parameter",
+		Category:    "architecture",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		return pass.Analyzer.AnalyzePackageAPIDesign(pass.File, pass.Files)
+	}))
+
+	Register(NewRule("concurrent_map_access", RuleMeta{
+		Description: "Maps shared with goroutines must be protected by a mutex",
+		Category:    "concurrency",
+		Severity:    "error",
+	}, func(pass *Pass) []ast.Issue {
+		return pass.Analyzer.AnalyzePackageConcurrencySafety(pass.File, pass.Files)
+	}))
+
+	Register(NewRule("goroutine_lifecycle", RuleMeta{
+		Description: "Goroutines must be stoppable, counted correctly by WaitGroups and free of leaking sends",
+		Category:    "concurrency",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		// Buffers that are not on disk, such as code sent to the MCP
+		// server, belong to no module; the version would otherwise come
+		// from the go.mod above the working directory.
+		goVersion := ""
+		if _, err := os.Stat(pass.Filename); err == nil {
+			goVersion = ast.ModuleGoVersion(filepath.Dir(pass.Filename))
+		}
+		return pass.Analyzer.AnalyzeGoroutines(pass.File, pass.Files, goVersion)
+	}))
+
+	Register(NewRule("secure_coding", RuleMeta{
+		Description: "Weak cryptography, SQL injection and hardcoded credentials",
+		Category:    "security",
+		Severity:    "error",
+	}, func(pass *Pass) []ast.Issue {
+		return pass.Analyzer.AnalyzeSecurityIssues(pass.File)
+	}))
+
+	Register(NewRule("no_panic", RuleMeta{
+		Description: "No panic, os.Exit or log.Fatal outside main packages and init",
+		Category:    "reliability",
+		Severity:    "error",
+	}, func(pass *Pass) []ast.Issue {
+		allow := ast.DefaultPanicAllowlist
+		if pass.Rule != nil && pass.Rule.Allow != nil {
+			allow = pass.Rule.Allow
+		}
+		return pass.Analyzer.AnalyzePanics(pass.File, allow)
+	}))
+
+	Register(NewRule("org_coding_standards", RuleMeta{
+		Description: "No exported globals and no snake_case function names",
+		Category:    "organization",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		return pass.Analyzer.AnalyzePackageOrganizationStandards(pass.File, pass.Files)
+	}))
+}
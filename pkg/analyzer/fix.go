@@ -0,0 +1,198 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+// maxFixRounds bounds how often Fix re-analyzes a file to apply fixes that
+// conflicted with those of an earlier round.
+const maxFixRounds = 4
+
+// ApplyFixes applies the first suggested fix of each issue to src and
+// formats the result with gofmt. A fix whose edits overlap those of a fix
+// already taken is skipped; identical edits, such as two fixes adding the
+// same import, are applied once. It returns the new source and the issues
+// whose fixes were applied, or src unchanged when there was nothing to fix.
+func ApplyFixes(src []byte, issues []ast.Issue) ([]byte, []ast.Issue, error) {
+	issues = append([]ast.Issue(nil), issues...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Position.Offset < issues[j].Position.Offset
+	})
+
+	var edits []ast.TextEdit
+	var applied []ast.Issue
+	for _, issue := range issues {
+		if len(issue.SuggestedFixes) == 0 {
+			continue
+		}
+		fix := issue.SuggestedFixes[0]
+		if !fitsFile(fix, issue.Position.Filename, len(src)) || conflicts(edits, fix.TextEdits) {
+			continue
+		}
+
+		for _, edit := range fix.TextEdits {
+			if !containsEdit(edits, edit) {
+				edits = append(edits, edit)
+			}
+		}
+		applied = append(applied, issue)
+	}
+	if len(edits) == 0 {
+		return src, nil, nil
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Pos.Offset < edits[j].Pos.Offset
+	})
+
+	var out bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		out.Write(src[last:edit.Pos.Offset])
+		out.Write(edit.NewText)
+		last = edit.End.Offset
+	}
+	out.Write(src[last:])
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format fixed source: %w", err)
+	}
+	return formatted, applied, nil
+}
+
+// fitsFile reports whether every edit of fix lies within the file.
+func fitsFile(fix ast.SuggestedFix, filename string, size int) bool {
+	for _, edit := range fix.TextEdits {
+		if edit.Pos.Filename != filename || edit.Pos.Offset > edit.End.Offset || edit.End.Offset > size {
+			return false
+		}
+	}
+	return true
+}
+
+// conflicts reports whether any of the edits overlaps an accepted edit.
+// Insertions at the same offset do not conflict.
+func conflicts(accepted, edits []ast.TextEdit) bool {
+	for _, edit := range edits {
+		if containsEdit(accepted, edit) {
+			continue
+		}
+		for _, a := range accepted {
+			if edit.Pos.Offset < a.End.Offset && a.Pos.Offset < edit.End.Offset {
+				return true
+			}
+			// Two replacements of the same range differ
+			if edit.Pos.Offset == a.Pos.Offset && edit.End.Offset == a.End.Offset && edit.Pos.Offset != edit.End.Offset {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsEdit(edits []ast.TextEdit, edit ast.TextEdit) bool {
+	for _, e := range edits {
+		if e.Pos.Offset == edit.Pos.Offset && e.End.Offset == edit.End.Offset && bytes.Equal(e.NewText, edit.NewText) {
+			return true
+		}
+	}
+	return false
+}
+
+// FixFiles applies the suggested fixes for the issues found in the given
+// files. Each file is analyzed together with the rest of its package, test
+// files included, so that fixes which change a declaration, such as a
+// rename, see every use of it; only the given files are changed. The files
+// are re-analyzed after each round so that fixes skipped for overlapping an
+// earlier one are applied in a later round. It returns the fixed source of
+// each file that changed and the issues that were fixed.
+func (e *AnalyzerEngine) FixFiles(paths []string, ruleIDs []string) (map[string][]byte, []ast.Issue, error) {
+	ruleIDs, err := e.ResolveRuleIDs(ruleIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := make(map[string]bool)
+	sources := make(map[string][]byte)
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		targets[path] = true
+
+		pkgFiles, err := ast.GoFiles(filepath.Dir(path), true)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range append(pkgFiles, path) {
+			if _, ok := sources[file]; ok {
+				continue
+			}
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read file: %w", err)
+			}
+			sources[file] = src
+		}
+	}
+
+	original := make(map[string][]byte, len(targets))
+	for path := range targets {
+		original[path] = sources[path]
+	}
+
+	var fixed []ast.Issue
+	for round := 0; round < maxFixRounds; round++ {
+		pkgs, err := e.analyzer.ParseSources(sources)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		byFile := make(map[string][]ast.Issue)
+		for _, issue := range e.analyzePackages(pkgs, ruleIDs).Issues {
+			if targets[issue.Position.Filename] {
+				byFile[issue.Position.Filename] = append(byFile[issue.Position.Filename], issue)
+			}
+		}
+
+		progress := false
+		for _, path := range sortedKeys(byFile) {
+			out, applied, err := ApplyFixes(sources[path], byFile[path])
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(applied) == 0 {
+				continue
+			}
+			sources[path] = out
+			fixed = append(fixed, applied...)
+			progress = true
+		}
+		if !progress {
+			break
+		}
+	}
+
+	changed := make(map[string][]byte)
+	for path, src := range original {
+		if !bytes.Equal(sources[path], src) {
+			changed[path] = sources[path]
+		}
+	}
+	return changed, fixed, nil
+}
+
+func sortedKeys(m map[string][]ast.Issue) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
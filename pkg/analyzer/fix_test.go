@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+	goast "go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+func TestApplyFixes(t *testing.T) {
+	src := []byte("package demo\n\nvar a, b = 1, 2\n")
+	edit := func(pos, end int, text string) ast.TextEdit {
+		return ast.TextEdit{
+			Pos:     token.Position{Filename: "demo.go", Offset: pos},
+			End:     token.Position{Filename: "demo.go", Offset: end},
+			NewText: []byte(text),
+		}
+	}
+	issue := func(offset int, edits ...ast.TextEdit) ast.Issue {
+		return ast.Issue{
+			Position:       token.Position{Filename: "demo.go", Offset: offset},
+			SuggestedFixes: []ast.SuggestedFix{{TextEdits: edits}},
+		}
+	}
+
+	got, applied, err := ApplyFixes(src, []ast.Issue{
+		issue(18, edit(18, 19, "x"), edit(12, 12, "\n\n// shared")),
+		issue(14, edit(18, 19, "y")),
+		issue(21, edit(21, 22, "z"), edit(12, 12, "\n\n// shared")),
+		{Position: token.Position{Filename: "demo.go", Offset: 25}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyFixes: %v", err)
+	}
+
+	// The second issue is sorted first and wins the overlapping edit
+	want := "package demo\n\n// shared\n\nvar y, z = 1, 2\n"
+	if string(got) != want {
+		t.Errorf("fixed source = %q, want %q", got, want)
+	}
+	if len(applied) != 2 || applied[0].Position.Offset != 14 || applied[1].Position.Offset != 21 {
+		t.Errorf("unexpected applied issues: %+v", applied)
+	}
+
+	got, applied, err = ApplyFixes(src, nil)
+	if err != nil || string(got) != string(src) || applied != nil {
+		t.Errorf("expected the source unchanged without fixes, got %q, %v, %v", got, applied, err)
+	}
+}
+
+func TestEngineFix(t *testing.T) {
+	src := `package demo
+
+import "os"
+
+type Store struct{}
+
+func (s *Store) Load(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	return data, nil
+}
+
+func load_all(s *Store) {
+	s.Load("a")
+}
+`
+	// Both fixes add to the single import line, so the second one is
+	// applied in another round
+	want := `package demo
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+type Store struct{}
+
+func (s *Store) Load(ctx context.Context, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return data, nil
+}
+
+func loadAll(s *Store) {
+	s.Load(context.TODO(), "a")
+}
+`
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"demo.go": src})
+	path := filepath.Join(dir, "demo.go")
+
+	engine := NewAnalyzerEngine()
+	files, fixed, err := engine.FixFiles([]string{path}, []string{"error_handling", "api_design", "org_coding_standards"})
+	if err != nil {
+		t.Fatalf("FixFiles: %v", err)
+	}
+	got := files[path]
+	if string(got) != want {
+		t.Errorf("fixed source:\n%s\nwant:\n%s", got, want)
+	}
+	if len(fixed) != 3 {
+		t.Errorf("expected 3 fixed issues, got %+v", fixed)
+	}
+	typeCheck(t, got)
+}
+
+func TestEngineFixKeepsPackageCompiling(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"store.go": `package demo
+
+import "context"
+
+type Store struct{}
+
+func (s *Store) Get(id string, ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Store) Put(id string) error {
+	return nil
+}
+
+func (s *Store) Delete(id string) error {
+	return nil
+}
+
+func load_all(s *Store) {
+	del := s.Delete
+	del("a")
+}
+`,
+		"use.go": `package demo
+
+func use(s *Store) {
+	s.Put("a")
+	load_all(s)
+}
+`,
+	})
+
+	engine, err := NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: true}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	result, err := engine.AnalyzePackage(dir, []string{"api_design", "org_coding_standards"})
+	if err != nil {
+		t.Fatalf("AnalyzePackage: %v", err)
+	}
+
+	// Get already takes a context, Put is called from another file, Delete
+	// is used as a value and load_all is called from another file
+	if len(result.Issues) != 4 {
+		t.Fatalf("Expected 4 issues, got %+v", result.Issues)
+	}
+	for _, issue := range result.Issues {
+		if len(issue.SuggestedFixes) != 0 {
+			t.Errorf("%s: expected no fix for %q, got %+v", issue.Position, issue.Description, issue.SuggestedFixes)
+		}
+	}
+}
+
+func TestEngineFixFilesSeesWholePackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": `package demo
+
+func do_work() {}
+
+func run_all() {
+	do_work()
+}
+`,
+		"b.go": `package demo
+
+func start() {
+	do_work()
+}
+`,
+	})
+
+	// Only a.go is fixed, but b.go still calls do_work
+	path := filepath.Join(dir, "a.go")
+	engine := NewAnalyzerEngine()
+	files, fixed, err := engine.FixFiles([]string{path}, []string{"org_coding_standards"})
+	if err != nil {
+		t.Fatalf("FixFiles: %v", err)
+	}
+
+	want := `package demo
+
+func do_work() {}
+
+func runAll() {
+	do_work()
+}
+`
+	if len(files) != 1 || string(files[path]) != want {
+		t.Errorf("fixed files = %q, want only a.go renaming run_all", files)
+	}
+	if len(fixed) != 1 {
+		t.Errorf("expected 1 fixed issue, got %+v", fixed)
+	}
+}
+
+// typeCheck fails the test unless src, a file of a package of its own,
+// compiles.
+func typeCheck(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "fixed.go", src, 0)
+	if err != nil {
+		t.Fatalf("Fixed source does not parse: %v", err)
+	}
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("demo", fset, []*goast.File{file}, nil); err != nil {
+		t.Errorf("Fixed source does not compile: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"fmt"
+	goast "go/ast"
+	"sort"
+	"sync"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+// RuleMeta describes a registered analysis.
+type RuleMeta struct {
+	Description string
+	Category    string
+	// Severity is the severity the analysis reports all of its findings
+	// with. The audit compares it with the severity of the YAML rule.
+	Severity string
+}
+
+// Pass holds the inputs of a single rule run over a parsed file. Rules report
+// issues for File only; Files gives them the rest of the package for context.
+type Pass struct {
+	Analyzer *ast.Analyzer
+	Filename string
+	File     *goast.File
+	Src      []byte
+	Files    []*goast.File
+	// Rule is the YAML rule being enforced, or nil when the engine has no
+	// registry or the analysis has no rule.
+	Rule *rules.Rule
+}
+
+// Rule is an analysis that can be registered with the engine. The rule's ID
+// matches the id of the YAML rule it enforces.
+type Rule interface {
+	ID() string
+	Meta() RuleMeta
+	Check(pass *Pass) []ast.Issue
+}
+
+var (
+	rulesMu    sync.RWMutex
+	registered = make(map[string]Rule)
+)
+
+// Register makes a rule available to the engine and the CLIs. It is intended
+// to be called from init functions and panics if the ID is already taken.
+func Register(rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if _, dup := registered[rule.ID()]; dup {
+		panic(fmt.Sprintf("analyzer: Register called twice for rule %s", rule.ID()))
+	}
+	registered[rule.ID()] = rule
+}
+
+// Lookup returns the registered rule with the given ID.
+func Lookup(id string) (Rule, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	rule, ok := registered[id]
+	return rule, ok
+}
+
+// Rules returns all registered rules sorted by ID.
+func Rules() []Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	list := make([]Rule, 0, len(registered))
+	for _, rule := range registered {
+		list = append(list, rule)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID() < list[j].ID()
+	})
+	return list
+}
+
+type funcRule struct {
+	id    string
+	meta  RuleMeta
+	check func(pass *Pass) []ast.Issue
+}
+
+// NewRule adapts a check function into a Rule.
+func NewRule(id string, meta RuleMeta, check func(pass *Pass) []ast.Issue) Rule {
+	return &funcRule{id: id, meta: meta, check: check}
+}
+
+func (r *funcRule) ID() string                   { return r.id }
+func (r *funcRule) Meta() RuleMeta               { return r.meta }
+func (r *funcRule) Check(pass *Pass) []ast.Issue { return r.check(pass) }
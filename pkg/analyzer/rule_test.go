@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	goast "go/ast"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func TestRegisteredRuleRunsInEngine(t *testing.T) {
+	Register(NewRule("test_no_init", RuleMeta{
+		Description: "Forbids init functions",
+		Category:    "organization",
+		Severity:    "warning",
+	}, func(pass *Pass) []ast.Issue {
+		var issues []ast.Issue
+		for _, decl := range pass.File.Decls {
+			if fn, ok := decl.(*goast.FuncDecl); ok && fn.Name.Name == "init" {
+				issues = append(issues, ast.Issue{
+					RuleID:      "test_no_init",
+					Description: "init function found",
+					Severity:    "warning",
+					Position:    pass.Analyzer.GetPositionOf(fn),
+				})
+			}
+		}
+		return issues
+	}))
+	t.Cleanup(func() { unregister("test_no_init") })
+
+	if _, ok := Lookup("test_no_init"); !ok {
+		t.Fatal("Expected registered rule to be found")
+	}
+
+	engine := NewAnalyzerEngine()
+	result, err := engine.Analyze("test.go", []byte("package test\n\nfunc init() {}\n"), []string{"test_no_init"})
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	if result.Valid || len(result.Issues) != 1 {
+		t.Fatalf("Expected one issue, got: %+v", result.Issues)
+	}
+	if result.Issues[0].Position.Line != 3 {
+		t.Errorf("Expected issue on line 3, got %d", result.Issues[0].Position.Line)
+	}
+}
+
+func TestBuiltinRulesRegistered(t *testing.T) {
+	for _, id := range []string{"error_handling", "discarded_errors", "error_wrapping", "api_design", "concurrent_map_access", "goroutine_lifecycle", "secure_coding", "no_panic", "org_coding_standards"} {
+		if _, ok := Lookup(id); !ok {
+			t.Errorf("Built-in rule %s is not registered", id)
+		}
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Register to panic on duplicate id")
+		}
+	}()
+
+	Register(NewRule("error_handling", RuleMeta{}, func(*Pass) []ast.Issue { return nil }))
+}
+
+func TestRuleAllowlistFromYAML(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"discarded_errors.yaml": `id: discarded_errors
+description: Errors must not be dropped
+severity: warning
+allow:
+  - os.Remove
+`,
+	})
+
+	registry, err := rules.NewRuleRegistry(dir)
+	if err != nil {
+		t.Fatalf("NewRuleRegistry: %v", err)
+	}
+	engine, err := NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: true}, registry)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	src := `package cleanup
+
+import (
+	"fmt"
+	"os"
+)
+
+func run() {
+	os.Remove("tmp")
+	fmt.Println("done")
+}
+`
+	result, err := engine.Analyze("cleanup.go", []byte(src), []string{"discarded_errors"})
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	// The YAML allowlist replaces the default one, which accepts fmt.Println
+	if len(result.Issues) != 1 || result.Issues[0].Description != "Error returned by fmt.Println is discarded" {
+		t.Errorf("Expected only fmt.Println to be reported, got %+v", result.Issues)
+	}
+}
+
+// unregister removes a rule registered by a test so that it does not leak
+// into the rules other tests see.
+func unregister(id string) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	delete(registered, id)
+}
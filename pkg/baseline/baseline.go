@@ -0,0 +1,211 @@
+// Package baseline records known findings so that validation can report only
+// the ones introduced since.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+)
+
+// Version is the format version written to baseline files.
+const Version = 1
+
+// Entry is a known finding. Findings are matched by rule, file and a
+// fingerprint of the offending code rather than by line, so that an entry
+// survives edits elsewhere in the file.
+type Entry struct {
+	RuleID      string `json:"ruleId"`
+	File        string `json:"file"`
+	Fingerprint string `json:"fingerprint"`
+	// Description is informational and not used for matching
+	Description string `json:"description"`
+	// Count is the number of identical findings
+	Count int `json:"count"`
+}
+
+// Baseline is a snapshot of the findings of a validation run.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// New records the findings in results. Files that could not be validated are
+// left out.
+func New(results []report.FileResult) (*Baseline, error) {
+	counts := make(map[Entry]int)
+	for _, f := range results {
+		if f.Err != nil || len(f.Issues) == 0 {
+			continue
+		}
+
+		entries, err := fileEntries(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			counts[entry]++
+		}
+	}
+
+	b := &Baseline{Version: Version, Entries: []Entry{}}
+	for entry, count := range counts {
+		entry.Count = count
+		b.Entries = append(b.Entries, entry)
+	}
+	sortEntries(b.Entries)
+	return b, nil
+}
+
+// Load reads a baseline file.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", b.Version, path)
+	}
+	return &b, nil
+}
+
+// Write saves the baseline to path.
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Filter removes the findings recorded in the baseline from results and
+// returns the remaining results along with the stale entries: those that no
+// longer match a finding, with Count set to the number of unmatched
+// findings. Entries for files outside results, or for rules outside ruleIDs
+// when it is not empty, are not considered stale since they were not checked.
+func (b *Baseline) Filter(results []report.FileResult, ruleIDs []string) ([]report.FileResult, []Entry, error) {
+	remaining := make(map[Entry]int)
+	for _, entry := range b.Entries {
+		count := entry.Count
+		entry.Count, entry.Description = 0, ""
+		remaining[entry] += count
+	}
+
+	checked := make(map[string]bool)
+	filtered := make([]report.FileResult, 0, len(results))
+	for _, f := range results {
+		if f.Err != nil {
+			filtered = append(filtered, f)
+			continue
+		}
+		checked[filePath(f.Path)] = true
+		if len(f.Issues) == 0 {
+			filtered = append(filtered, f)
+			continue
+		}
+
+		entries, err := fileEntries(f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		kept := report.FileResult{Path: f.Path}
+		for i, issue := range f.Issues {
+			key := entries[i]
+			key.Description = ""
+			if remaining[key] > 0 {
+				remaining[key]--
+				continue
+			}
+			kept.Issues = append(kept.Issues, issue)
+		}
+		filtered = append(filtered, kept)
+	}
+
+	var stale []Entry
+	for _, entry := range b.Entries {
+		key := entry
+		key.Count, key.Description = 0, ""
+		if remaining[key] == 0 || !checked[entry.File] || (len(ruleIDs) > 0 && !slices.Contains(ruleIDs, entry.RuleID)) {
+			continue
+		}
+		entry.Count = min(entry.Count, remaining[key])
+		remaining[key] -= entry.Count
+		stale = append(stale, entry)
+	}
+	return filtered, stale, nil
+}
+
+// Fingerprint identifies a finding by its rule, its description and the
+// source line it was reported on, with whitespace normalized.
+func Fingerprint(issue ast.Issue, src []byte) string {
+	code := ""
+	if lines := strings.Split(string(src), "\n"); issue.Position.Line > 0 && issue.Position.Line <= len(lines) {
+		code = strings.Join(strings.Fields(lines[issue.Position.Line-1]), " ")
+	}
+
+	sum := sha256.Sum256([]byte(issue.RuleID + "\x00" + issue.Description + "\x00" + code))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fileEntries returns an entry for each issue of the file, in order.
+func fileEntries(f report.FileResult) ([]Entry, error) {
+	src, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	entries := make([]Entry, len(f.Issues))
+	for i, issue := range f.Issues {
+		entries[i] = Entry{
+			RuleID:      issue.RuleID,
+			File:        filePath(f.Path),
+			Fingerprint: Fingerprint(issue, src),
+			Description: issue.Description,
+		}
+	}
+	return entries, nil
+}
+
+// filePath returns the slash-separated form of path relative to the working
+// directory, so that baselines can be shared across checkouts.
+func filePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+}
@@ -0,0 +1,109 @@
+package baseline
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/report"
+)
+
+func issueAt(path, ruleID, description string, line int) ast.Issue {
+	return ast.Issue{
+		RuleID:      ruleID,
+		Description: description,
+		Severity:    "warning",
+		Position:    token.Position{Filename: path, Line: line},
+	}
+}
+
+func TestBaselineFilter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	other := filepath.Join(dir, "b.go")
+
+	before := "package a\n\nfunc do_a() {}\n\nvar Global = 1\n\nfunc do_c() {}\n"
+	if err := os.WriteFile(path, []byte(before), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("package a\n\nfunc do_x() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := New([]report.FileResult{
+		{Path: path, Issues: []ast.Issue{
+			issueAt(path, "org_coding_standards", "snake_case", 3),
+			issueAt(path, "org_coding_standards", "global", 5),
+			issueAt(path, "error_handling", "unchecked", 7),
+		}},
+		{Path: other, Issues: []ast.Issue{issueAt(other, "org_coding_standards", "snake_case", 3)}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create baseline: %v", err)
+	}
+
+	baselineFile := filepath.Join(dir, "baseline.json")
+	if err := b.Write(baselineFile); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = Load(baselineFile); err != nil {
+		t.Fatalf("Failed to load baseline: %v", err)
+	}
+	if len(b.Entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %+v", b.Entries)
+	}
+
+	// Lines shift, the global is removed and a new snake_case function is
+	// added, duplicating a known line
+	after := "package a\n\n// Package a.\n\nfunc do_a() {}\n\nfunc do_c() {}\n\nfunc do_a() {}\n"
+	if err := os.WriteFile(path, []byte(after), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, stale, err := b.Filter([]report.FileResult{
+		{Path: path, Issues: []ast.Issue{
+			issueAt(path, "org_coding_standards", "snake_case", 5),
+			issueAt(path, "error_handling", "unchecked", 7),
+			issueAt(path, "org_coding_standards", "snake_case", 9),
+		}},
+	}, []string{"org_coding_standards", "error_handling"})
+	if err != nil {
+		t.Fatalf("Filter failed: %v", err)
+	}
+
+	if len(results) != 1 || len(results[0].Issues) != 1 || results[0].Issues[0].Position.Line != 9 {
+		t.Fatalf("Expected only the new finding on line 9, got %+v", results)
+	}
+
+	// b.go was not validated, so its entry is not stale
+	if len(stale) != 1 || stale[0].Description != "global" || stale[0].Count != 1 {
+		t.Errorf("Expected the global entry to be stale, got %+v", stale)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	issue := issueAt("a.go", "error_handling", "unchecked", 2)
+	base := Fingerprint(issue, []byte("package a\n\tos.Remove(path)\n"))
+
+	tests := []struct {
+		name  string
+		issue ast.Issue
+		src   string
+		same  bool
+	}{
+		{"moved and reindented", issueAt("a.go", "error_handling", "unchecked", 4), "package a\n\n\n    os.Remove(path)\n", true},
+		{"changed code", issue, "package a\n\tos.Remove(other)\n", false},
+		{"other rule", issueAt("a.go", "secure_coding", "unchecked", 2), "package a\n\tos.Remove(path)\n", false},
+		{"other description", issueAt("a.go", "error_handling", "ignored", 2), "package a\n\tos.Remove(path)\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fingerprint(tt.issue, []byte(tt.src)) == base; got != tt.same {
+				t.Errorf("Expected same fingerprint: %v, got %v", tt.same, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package diff renders line-based unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// maxCells bounds the size of the table used to align the changed region of
+// two files. Larger regions are shown as one replacement.
+const maxCells = 1 << 24
+
+type opKind int
+
+const (
+	equal opKind = iota
+	del
+	ins
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns the unified diff that turns old into new, with the given
+// file names in its header, or nil when the two are equal.
+func Unified(oldName, newName string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	ops := lineOps(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Walk the ops, grouping changes that are at most 2*context lines
+	// apart into one hunk.
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == equal {
+			i++
+			oldLine++
+			newLine++
+			continue
+		}
+
+		start := i
+		for start > 0 && i-start < context && ops[start-1].kind == equal {
+			start--
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == equal {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, run)
+				break
+			}
+			end = run
+		}
+
+		lead := i - start
+		hunkOld, hunkNew := oldLine-lead, newLine-lead
+		var oldCount, newCount int
+		var body strings.Builder
+		for _, o := range ops[start:end] {
+			switch o.kind {
+			case equal:
+				body.WriteString(" " + o.line)
+				oldCount++
+				newCount++
+			case del:
+				body.WriteString("-" + o.line)
+				oldCount++
+			case ins:
+				body.WriteString("+" + o.line)
+				newCount++
+			}
+			if !strings.HasSuffix(o.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n%s", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount), body.String())
+
+		for _, o := range ops[i:end] {
+			if o.kind != ins {
+				oldLine++
+			}
+			if o.kind != del {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return []byte(b.String())
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range names the line before it
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits text after each newline; the last line may lack one.
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineOps aligns two sequences of lines into equal, deleted and inserted
+// lines using a longest common subsequence of the region between their
+// common prefix and suffix.
+func lineOps(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{equal, line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(midA)+1)*(len(midB)+1) > maxCells {
+		for _, line := range midA {
+			ops = append(ops, op{del, line})
+		}
+		for _, line := range midB {
+			ops = append(ops, op{ins, line})
+		}
+	} else {
+		ops = append(ops, lcsOps(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{equal, line})
+	}
+	return ops
+}
+
+func lcsOps(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{equal, a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			ops = append(ops, op{del, a[i]})
+			i++
+		default:
+			ops = append(ops, op{ins, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{del, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{ins, b[j]})
+	}
+	return ops
+}
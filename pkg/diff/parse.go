@@ -0,0 +1,141 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive range of line numbers.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes maps the slash-separated paths of the files in a diff to the lines
+// the diff adds to their new version. Removed lines have no counterpart in
+// the new file and are not recorded.
+type Changes map[string][]LineRange
+
+// Contains reports whether the diff added the given line of the file.
+func (c Changes) Contains(file string, line int) bool {
+	for _, r := range c[path.Clean(file)] {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads the added lines of each file from a unified diff, such as the
+// output of git diff or diff -u. The a/ and b/ prefixes git adds to paths are
+// removed; deleted files are skipped.
+func Parse(r io.Reader) (Changes, error) {
+	changes := make(Changes)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	file := ""
+	newLine, oldLeft, newLeft := 0, 0, 0
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+
+		// Inside a hunk, lines are consumed by count so that content
+		// starting with "---" or "+++" is not mistaken for a header
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if file != "" {
+					changes.add(file, newLine)
+				}
+				newLine++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				newLine++
+				oldLeft--
+				newLeft--
+			case strings.HasPrefix(line, `\`):
+			default:
+				return nil, fmt.Errorf("line %d: unexpected line in hunk: %q", lineNo, line)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = diffPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			if oldLeft, newLine, newLeft, err = parseHunkHeader(line); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+	return changes, nil
+}
+
+// add records a line, extending the file's last range when adjacent.
+func (c Changes) add(file string, line int) {
+	ranges := c[file]
+	if n := len(ranges); n > 0 && ranges[n-1].End == line-1 {
+		ranges[n-1].End = line
+		return
+	}
+	c[file] = append(ranges, LineRange{line, line})
+}
+
+// diffPath returns the path of a ---/+++ header, or "" for /dev/null.
+func diffPath(header string) string {
+	// A tab separates the path from an optional timestamp
+	name, _, _ := strings.Cut(header, "\t")
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		name = unquoted
+	}
+	if name == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		name = name[2:]
+	}
+	return path.Clean(name)
+}
+
+// parseHunkHeader parses "@@ -start,count +start,count @@" and returns the
+// number of old lines, the first new line and the number of new lines.
+func parseHunkHeader(header string) (oldCount, newStart, newCount int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header: %q", header)
+	}
+
+	if _, oldCount, err = parseRange(fields[1][1:]); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %w", header, err)
+	}
+	if newStart, newCount, err = parseRange(fields[2][1:]); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %w", header, err)
+	}
+	return oldCount, newStart, newCount, nil
+}
+
+// parseRange parses "start,count" or "start", whose count is 1.
+func parseRange(s string) (start, count int, err error) {
+	startText, countText, found := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startText); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if found {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
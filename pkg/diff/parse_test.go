@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want Changes
+	}{
+		{
+			name: "git diff",
+			diff: `diff --git a/pkg/a.go b/pkg/a.go
+index 3b18e51..a8c2b2d 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -2,6 +2,8 @@ package a
+
+ func f() {
+-	old()
++	first()
++	second()
+ 	keep()
++	third()
+ }
+
+@@ -20 +22 @@ func g() {
+-	x := 1
++	x := 2
+`,
+			want: Changes{"pkg/a.go": {{4, 5}, {7, 7}, {22, 22}}},
+		},
+		{
+			name: "new and deleted files",
+			diff: `diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,3 @@
++package a
++
++var x = 1
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+--- a/gone.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package a
+-
+`,
+			want: Changes{"new.go": {{1, 3}}},
+		},
+		{
+			name: "hunk content that looks like a header",
+			diff: `--- a.go	2024-01-01 00:00:00
++++ a.go	2024-01-02 00:00:00
+@@ -1,2 +1,2 @@
+--- a comment
++++ a comment
+ x
+`,
+			want: Changes{"a.go": {{1, 1}}},
+		},
+		{
+			name: "unified output of this package",
+			diff: string(Unified("a.go.orig", "a.go", []byte("1\n2\n3\n4\n5\n6\n7\n8\n"), []byte("1\n2\n3\n4\nx\n5\n6\n7\n8\n"))),
+			want: Changes{"a.go": {{5, 5}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.diff))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseInvalidHunk(t *testing.T) {
+	if _, err := Parse(strings.NewReader("+++ b/a.go\n@@ -x +1 @@\n")); err == nil {
+		t.Error("Expected an error for an invalid hunk header")
+	}
+}
+
+func TestChangesContains(t *testing.T) {
+	changes := Changes{"pkg/a.go": {{4, 5}}}
+
+	tests := []struct {
+		file string
+		line int
+		want bool
+	}{
+		{"pkg/a.go", 4, true},
+		{"pkg/a.go", 5, true},
+		{"./pkg/a.go", 5, true},
+		{"pkg/a.go", 6, false},
+		{"pkg/b.go", 4, false},
+	}
+
+	for _, tt := range tests {
+		if got := changes.Contains(tt.file, tt.line); got != tt.want {
+			t.Errorf("Contains(%s, %d) = %v, want %v", tt.file, tt.line, got, tt.want)
+		}
+	}
+}
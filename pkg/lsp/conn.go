@@ -0,0 +1,112 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Conn reads and writes LSP base protocol messages: JSON-RPC 2.0 payloads
+// preceded by a Content-Length header. Writes are safe for concurrent use.
+type Conn struct {
+	r *bufio.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{r: bufio.NewReader(r), w: w}
+}
+
+// Read returns the next message. It returns io.EOF when the stream ends
+// between messages.
+func (c *Conn) Read() (*Message, error) {
+	data, err := c.ReadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	return &msg, nil
+}
+
+// ReadRaw returns the payload of the next message without decoding it.
+func (c *Conn) ReadRaw() ([]byte, error) {
+	header, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length: %q", header.Get("Content-Length"))
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return data, nil
+}
+
+// Write sends a message.
+func (c *Conn) Write(msg *Message) error {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return c.WriteRaw(data)
+}
+
+// WriteRaw sends an already encoded payload.
+func (c *Conn) WriteRaw(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if _, err := c.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Notify sends a notification.
+func (c *Conn) Notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params: %w", err)
+	}
+	return c.Write(&Message{Method: method, Params: raw})
+}
+
+// Reply answers the request with the given id.
+func (c *Conn) Reply(id json.RawMessage, result interface{}) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	return c.Write(&Message{ID: id, Result: raw})
+}
+
+// ReplyError answers the request with the given id with an error.
+func (c *Conn) ReplyError(id json.RawMessage, code int, message string) error {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return c.Write(&Message{ID: id, Error: &ResponseError{Code: code, Message: message}})
+}
@@ -0,0 +1,56 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+// Source is the diagnostic source reported for governance findings.
+const Source = "mcplsp"
+
+// Diagnostics converts analyzer issues for doc into diagnostics. Issues only
+// carry a start position, so each range extends to the end of its line.
+func Diagnostics(doc *Document, issues []ast.Issue) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, issue := range issues {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    IssueRange(doc, issue),
+			Severity: DiagnosticSeverity(issue.Severity),
+			Code:     issue.RuleID,
+			Source:   Source,
+			Message:  issue.Description,
+		})
+	}
+	return diagnostics
+}
+
+// IssueRange returns the range an issue covers in doc: from its position to
+// the end of the line, without trailing whitespace.
+func IssueRange(doc *Document, issue ast.Issue) Range {
+	line := issue.Position.Line
+	if line < 1 {
+		line = 1
+	}
+	text := strings.TrimRight(doc.Line(line), " \t")
+
+	start := doc.Position(line, issue.Position.Column)
+	end := doc.Position(line, len(text)+1)
+	if end.Character < start.Character {
+		end = start
+	}
+	return Range{Start: start, End: end}
+}
+
+// DiagnosticSeverity maps rule severities onto LSP severities.
+func DiagnosticSeverity(severity string) int {
+	switch strings.ToLower(severity) {
+	case "error":
+		return SeverityError
+	case "warning":
+		return SeverityWarning
+	case "info":
+		return SeverityInformation
+	}
+	return SeverityHint
+}
@@ -0,0 +1,139 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Document is an open text buffer.
+type Document struct {
+	URI     string
+	Version int
+	Text    string
+}
+
+// Path returns the file system path of the document's file URI.
+func (d *Document) Path() string {
+	return URIToPath(d.URI)
+}
+
+// ApplyChanges applies content changes in order.
+func (d *Document) ApplyChanges(changes []TextDocumentContentChangeEvent) error {
+	for _, change := range changes {
+		if change.Range == nil {
+			d.Text = change.Text
+			continue
+		}
+
+		start, err := d.Offset(change.Range.Start)
+		if err != nil {
+			return err
+		}
+		end, err := d.Offset(change.Range.End)
+		if err != nil {
+			return err
+		}
+		if end < start {
+			return fmt.Errorf("invalid range: end before start")
+		}
+		d.Text = d.Text[:start] + change.Text + d.Text[end:]
+	}
+	return nil
+}
+
+// Offset converts an LSP position into a byte offset. Characters past the
+// end of a line clamp to the line end, as the specification requires.
+func (d *Document) Offset(pos Position) (int, error) {
+	if pos.Line < 0 || pos.Character < 0 {
+		return 0, fmt.Errorf("invalid position %d:%d", pos.Line, pos.Character)
+	}
+
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		i := strings.IndexByte(d.Text[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d out of range", pos.Line)
+		}
+		offset += i + 1
+	}
+
+	lineText := d.Text[offset:]
+	if i := strings.IndexByte(lineText, '\n'); i >= 0 {
+		lineText = lineText[:i]
+	}
+
+	units := 0
+	for i, r := range lineText {
+		if units >= pos.Character {
+			return offset + i, nil
+		}
+		units += utf16.RuneLen(r)
+	}
+	return offset + len(lineText), nil
+}
+
+// Position converts a 1-based line and byte column, as in token.Position,
+// into an LSP position.
+func (d *Document) Position(line, column int) Position {
+	text := d.Line(line)
+	col := column - 1
+	if col < 0 {
+		col = 0
+	}
+	if col > len(text) {
+		col = len(text)
+	}
+	return Position{Line: max(line-1, 0), Character: utf16Len(text[:col])}
+}
+
+// PositionOf converts a byte offset into an LSP position.
+func (d *Document) PositionOf(offset int) Position {
+	if offset > len(d.Text) {
+		offset = len(d.Text)
+	}
+	before := d.Text[:offset]
+	line := strings.Count(before, "\n")
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return Position{Line: line, Character: utf16Len(before[lineStart:])}
+}
+
+// Line returns the text of a 1-based line without its newline.
+func (d *Document) Line(line int) string {
+	lines := strings.Split(d.Text, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[line-1], "\r")
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		n += utf16.RuneLen(r)
+		s = s[size:]
+	}
+	return n
+}
+
+// URIToPath converts a file URI into a path. Other strings are returned
+// unchanged.
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// PathToURI converts a path into a file URI.
+func PathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,78 @@
+package lsp
+
+import "testing"
+
+func TestDocumentApplyChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		changes []TextDocumentContentChangeEvent
+		want    string
+	}{
+		{
+			name:    "full replacement",
+			text:    "package a\n",
+			changes: []TextDocumentContentChangeEvent{{Text: "package b\n"}},
+			want:    "package b\n",
+		},
+		{
+			name: "insert",
+			text: "package a\n\nfunc f() {}\n",
+			changes: []TextDocumentContentChangeEvent{{
+				Range: &Range{Start: Position{Line: 2, Character: 7}, End: Position{Line: 2, Character: 7}},
+				Text:  "ctx context.Context",
+			}},
+			want: "package a\n\nfunc f(ctx context.Context) {}\n",
+		},
+		{
+			// "é" is one UTF-16 unit but two bytes; "😀" is two units and four bytes
+			name: "utf16 offsets",
+			text: "s := \"é😀x\"\n",
+			changes: []TextDocumentContentChangeEvent{{
+				Range: &Range{Start: Position{Line: 0, Character: 9}, End: Position{Line: 0, Character: 10}},
+				Text:  "y",
+			}},
+			want: "s := \"é😀y\"\n",
+		},
+		{
+			name: "sequential edits",
+			text: "a\nb\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: &Range{Start: Position{Line: 1, Character: 0}, End: Position{Line: 1, Character: 1}}, Text: "c"},
+				{Range: &Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 1, Character: 0}}, Text: ""},
+			},
+			want: "c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Document{Text: tt.text}
+			if err := doc.ApplyChanges(tt.changes); err != nil {
+				t.Fatalf("ApplyChanges: %v", err)
+			}
+			if doc.Text != tt.want {
+				t.Errorf("got %q, want %q", doc.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentPosition(t *testing.T) {
+	doc := &Document{Text: "package a\n\nvar s = \"😀\"; var t int\n"}
+
+	// Byte column 20 follows the emoji, which is four bytes but two UTF-16 units
+	got := doc.Position(3, 20)
+	want := Position{Line: 2, Character: 17}
+	if got != want {
+		t.Errorf("Position(3, 20) = %+v, want %+v", got, want)
+	}
+
+	offset, err := doc.Offset(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.PositionOf(offset) != want {
+		t.Errorf("PositionOf(%d) = %+v, want %+v", offset, doc.PositionOf(offset), want)
+	}
+}
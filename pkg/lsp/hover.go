@@ -0,0 +1,103 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+)
+
+// hover documents the rules behind the findings at the requested position.
+func (s *Server) hover(params TextDocumentPositionParams) *Hover {
+	snapshot, issues, ok := s.current(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+
+	var sections []string
+	var covered *Range
+	seen := make(map[string]bool)
+	for _, issue := range issues {
+		r := IssueRange(&snapshot, issue)
+		if !contains(r, params.Position) || seen[issue.RuleID] {
+			continue
+		}
+		seen[issue.RuleID] = true
+		sections = append(sections, s.RuleDoc(issue.RuleID))
+		if covered == nil {
+			covered = &r
+		}
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: Markdown, Value: strings.Join(sections, "\n\n---\n\n")},
+		Range:    covered,
+	}
+}
+
+// RuleDoc renders the documentation of a rule as markdown. It is built from
+// the rule's YAML definition, the same file served as the rule resource,
+// and links the code template the rule names. Rules without a YAML
+// definition are documented from the registered analysis.
+func (s *Server) RuleDoc(id string) string {
+	var b strings.Builder
+
+	if s.config.Registry != nil {
+		if rule, ok := s.config.Registry.Lookup(id); ok {
+			fmt.Fprintf(&b, "**%s** · %s · %s\n\n%s", rule.ID, rule.Severity, rule.Category, rule.Description)
+			if rule.Rationale != "" {
+				fmt.Fprintf(&b, "\n\n*Rationale:* %s", rule.Rationale)
+			}
+
+			if len(rule.Checks) > 0 {
+				b.WriteString("\n\nChecks:\n")
+				for _, check := range rule.Checks {
+					fmt.Fprintf(&b, "\n- `%s`", check.Name)
+				}
+			}
+
+			var links []string
+			if link := s.templateLink(rule.Template); link != "" {
+				links = append(links, "Template: "+link)
+			}
+			if path, ok := s.config.Registry.Path(rule.ID); ok {
+				links = append(links, fmt.Sprintf("Rule: [%s](%s)", filepath.Base(path), PathToURI(path)))
+			}
+			if len(links) > 0 {
+				b.WriteString("\n\n" + strings.Join(links, " · "))
+			}
+			return b.String()
+		}
+	}
+
+	if rule, ok := analyzer.Lookup(id); ok {
+		meta := rule.Meta()
+		fmt.Fprintf(&b, "**%s** · %s · %s\n\n%s", id, meta.Severity, meta.Category, meta.Description)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "**%s**", id)
+	return b.String()
+}
+
+// templateLink links a template by name, e.g. go/service, to its file in
+// the templates directory.
+func (s *Server) templateLink(name string) string {
+	if name == "" || s.config.TemplatesDir == "" {
+		return ""
+	}
+	path := filepath.Join(s.config.TemplatesDir, filepath.FromSlash(name)+".tmpl")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("[%s](%s)", name, PathToURI(path))
+}
+
+func contains(r Range, pos Position) bool {
+	return !before(pos, r.Start) && !before(r.End, pos)
+}
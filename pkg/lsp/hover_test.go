@@ -0,0 +1,85 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func TestServerHover(t *testing.T) {
+	registry, err := rules.NewRuleRegistry("../../server/mcpserver/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const uri = "file:///work/demo/load.go"
+	server := NewServer(Config{
+		Registry:     registry,
+		RuleIDs:      []string{"error_handling"},
+		TemplatesDir: "../../server/mcpserver/templates",
+	})
+
+	messages := run(t, server, script(t,
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: uncheckedSrc},
+		}),
+		request(2, "textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 3, Character: 8},
+		}),
+		request(3, "textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 0, Character: 3},
+		}),
+		notification("exit", nil),
+	))
+
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(messages))
+	}
+
+	var init InitializeResult
+	if err := json.Unmarshal(messages[0].Result, &init); err != nil {
+		t.Fatal(err)
+	}
+	if !init.Capabilities.HoverProvider {
+		t.Errorf("expected the hover capability to be advertised: %+v", init.Capabilities)
+	}
+
+	var hover Hover
+	if err := json.Unmarshal(messages[2].Result, &hover); err != nil {
+		t.Fatal(err)
+	}
+	if hover.Contents.Kind != Markdown || hover.Range == nil || hover.Range.Start.Line != 3 {
+		t.Errorf("unexpected hover: %s", messages[2].Result)
+	}
+	for _, want := range []string{
+		"**error_handling** · warning · code_quality",
+		"Ensures proper error handling patterns in Go code",
+		"*Rationale:* Consistent error handling",
+		"- `no_ignored_errors`",
+		"- `propagate_with_context`",
+		"Template: [go/error_handler](file://",
+		"/templates/go/error_handler.tmpl)",
+		"Rule: [error_handling.yaml](file://",
+	} {
+		if !strings.Contains(hover.Contents.Value, want) {
+			t.Errorf("hover is missing %q:\n%s", want, hover.Contents.Value)
+		}
+	}
+
+	if string(messages[3].Result) != "null" {
+		t.Errorf("expected no hover outside a finding, got %s", messages[3].Result)
+	}
+}
+
+func TestRuleDocWithoutRegistry(t *testing.T) {
+	doc := NewServer(Config{}).RuleDoc("api_design")
+	want := "**api_design** · warning · architecture\n\nAPI methods take context.Context as their first parameter"
+	if doc != want {
+		t.Errorf("RuleDoc = %q, want %q", doc, want)
+	}
+}
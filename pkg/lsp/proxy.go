@@ -0,0 +1,429 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// exitTimeout bounds how long the proxy waits for the wrapped server to exit
+// once the client has gone.
+const exitTimeout = 5 * time.Second
+
+// ProxyConfig configures a Proxy.
+type ProxyConfig struct {
+	Config
+	// Command starts the wrapped language server, gopls by default. It
+	// must speak LSP on its stdin and stdout.
+	Command []string
+	// Stderr receives the wrapped server's stderr; os.Stderr by default.
+	Stderr io.Writer
+}
+
+// Proxy sits between an editor and another Go language server such as
+// gopls. All traffic is forwarded unchanged, except that governance findings
+// are merged into the wrapped server's diagnostics, governance quick fixes
+// are added to its code actions and rule documentation to its hovers.
+type Proxy struct {
+	config ProxyConfig
+	server *Server
+	client *Conn
+	child  *Conn
+	exited atomic.Bool
+	// replies tracks the augmented responses still being written
+	replies sync.WaitGroup
+
+	mu sync.Mutex
+	// pending holds the client requests whose responses are augmented
+	pending map[string]*Message
+	// childDiagnostics and ownDiagnostics hold the latest diagnostics of
+	// the wrapped server and of the governance analysis per document
+	childDiagnostics map[string][]json.RawMessage
+	ownDiagnostics   map[string][]json.RawMessage
+}
+
+func NewProxy(config ProxyConfig) *Proxy {
+	if len(config.Command) == 0 {
+		config.Command = []string{"gopls"}
+	}
+	if config.Stderr == nil {
+		config.Stderr = os.Stderr
+	}
+
+	p := &Proxy{
+		config:           config,
+		server:           NewServer(config.Config),
+		pending:          make(map[string]*Message),
+		childDiagnostics: make(map[string][]json.RawMessage),
+		ownDiagnostics:   make(map[string][]json.RawMessage),
+	}
+	p.server.publish = p.publishOwn
+	return p
+}
+
+// publishParams mirrors PublishDiagnosticsParams but keeps diagnostics
+// undecoded, so that fields the wrapped server sets survive the merge.
+type publishParams struct {
+	URI         string            `json:"uri"`
+	Version     *int              `json:"version,omitempty"`
+	Diagnostics []json.RawMessage `json:"diagnostics"`
+}
+
+// Serve starts the wrapped server and relays messages between it and the
+// client on r and w until the client exits, either side hangs up or ctx is
+// cancelled.
+func (p *Proxy) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	cmd := exec.Command(p.config.Command[0], p.config.Command[1:]...)
+	cmd.Stderr = p.config.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start language server: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start language server: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start language server: %w", err)
+	}
+
+	p.client = NewConn(r, w)
+	p.child = NewConn(stdout, stdin)
+	p.server.conn = p.client
+	defer p.replies.Wait()
+	defer p.server.stop()
+
+	clientDone := make(chan error, 1)
+	go func() { clientDone <- p.relayClient() }()
+	childDone := make(chan error, 1)
+	go func() { childDone <- p.relayChild() }()
+
+	select {
+	case err := <-clientDone:
+		// Closing stdin tells a server that missed exit to stop too
+		stdin.Close()
+		select {
+		case <-childDone:
+		case <-time.After(exitTimeout):
+			cmd.Process.Kill()
+			<-childDone
+		}
+		cmd.Wait()
+		return err
+
+	case err := <-childDone:
+		waitErr := cmd.Wait()
+		if p.exited.Load() {
+			return nil
+		}
+		if err == nil {
+			err = waitErr
+		}
+		if err == nil {
+			err = errors.New("exited before the client")
+		}
+		return fmt.Errorf("language server %s: %w", p.config.Command[0], err)
+
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		<-childDone
+		cmd.Wait()
+		return ctx.Err()
+	}
+}
+
+// documentMethods are the client messages the governance analysis follows.
+// They only record the buffers and schedule their analysis, so handling them
+// does not hold up forwarding. shutdown is left out: the server would wait
+// for its pending analyses.
+var documentMethods = map[string]bool{
+	"initialize":             true,
+	"textDocument/didOpen":   true,
+	"textDocument/didChange": true,
+	"textDocument/didSave":   true,
+	"textDocument/didClose":  true,
+}
+
+// relayClient forwards the client's messages to the wrapped server and keeps
+// the governance analysis of open documents up to date.
+func (p *Proxy) relayClient() error {
+	for {
+		data, err := p.client.ReadRaw()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("failed to decode message: %w", err)
+		}
+
+		if msg.IsRequest() {
+			switch msg.Method {
+			case "initialize", "textDocument/codeAction", "textDocument/hover":
+				p.mu.Lock()
+				p.pending[string(msg.ID)] = &msg
+				p.mu.Unlock()
+			}
+		}
+
+		if err := p.child.WriteRaw(data); err != nil {
+			return err
+		}
+
+		switch {
+		case msg.Method == "exit":
+			p.exited.Store(true)
+			return nil
+		case documentMethods[msg.Method]:
+			if _, err := p.server.dispatch(&msg); err != nil {
+				fmt.Fprintf(p.config.Stderr, "lsp proxy: %s: %v\n", msg.Method, err)
+			}
+		}
+	}
+}
+
+// relayChild forwards the wrapped server's messages to the client, merging
+// governance results into diagnostics and into the responses to the
+// requests recorded by relayClient.
+func (p *Proxy) relayChild() error {
+	for {
+		data, err := p.child.ReadRaw()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("failed to decode message: %w", err)
+		}
+
+		switch {
+		case msg.Method == "textDocument/publishDiagnostics":
+			var params publishParams
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				return fmt.Errorf("failed to decode diagnostics: %w", err)
+			}
+			p.mu.Lock()
+			p.childDiagnostics[params.URI] = params.Diagnostics
+			p.mu.Unlock()
+			err = p.publish(params.URI, params.Version)
+
+		case msg.IsResponse():
+			p.mu.Lock()
+			request := p.pending[string(msg.ID)]
+			delete(p.pending, string(msg.ID))
+			p.mu.Unlock()
+
+			switch {
+			case request == nil || msg.Error != nil:
+				err = p.client.WriteRaw(data)
+			case request.Method == "initialize":
+				err = p.reply(request, &msg, data)
+			default:
+				// Governance results may wait for a pending analysis of
+				// the document, which must not hold up other messages
+				p.replies.Add(1)
+				go func() {
+					defer p.replies.Done()
+					if err := p.reply(request, &msg, data); err != nil {
+						fmt.Fprintf(p.config.Stderr, "lsp proxy: %s: %v\n", request.Method, err)
+					}
+				}()
+			}
+
+		default:
+			err = p.client.WriteRaw(data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// reply forwards the wrapped server's response to request, with the
+// governance results added when there are any.
+func (p *Proxy) reply(request, msg *Message, data []byte) error {
+	if result, ok := p.augment(request, msg.Result); ok {
+		msg.Result = result
+		return p.client.Write(msg)
+	}
+	return p.client.WriteRaw(data)
+}
+
+// augment adds the governance results to the wrapped server's response to
+// request. It reports false when the response is to be forwarded unchanged.
+func (p *Proxy) augment(request *Message, result json.RawMessage) (json.RawMessage, bool) {
+	var augmented interface{}
+	var err error
+
+	switch request.Method {
+	case "initialize":
+		augmented, err = mergeCapabilities(result)
+
+	case "textDocument/codeAction":
+		var params CodeActionParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, false
+		}
+		actions := p.server.codeActions(params)
+		if len(actions) == 0 {
+			return nil, false
+		}
+		augmented, err = mergeCodeActions(result, actions)
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, false
+		}
+		hover := p.server.hover(params)
+		if hover == nil {
+			return nil, false
+		}
+		augmented, err = mergeHover(result, hover)
+	}
+	if err != nil || augmented == nil {
+		return nil, false
+	}
+
+	data, err := json.Marshal(augmented)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// mergeCapabilities makes sure the client asks for quick fixes and hovers,
+// which the proxy answers even when the wrapped server does not.
+func mergeCapabilities(result json.RawMessage) (interface{}, error) {
+	var init map[string]json.RawMessage
+	if err := json.Unmarshal(result, &init); err != nil {
+		return nil, err
+	}
+	var capabilities map[string]interface{}
+	if err := json.Unmarshal(init["capabilities"], &capabilities); err != nil || capabilities == nil {
+		capabilities = make(map[string]interface{})
+	}
+
+	switch provider := capabilities["codeActionProvider"].(type) {
+	case bool:
+		if !provider {
+			capabilities["codeActionProvider"] = CodeActionOptions{CodeActionKinds: []string{QuickFix}}
+		}
+	case map[string]interface{}:
+		if kinds, ok := provider["codeActionKinds"].([]interface{}); ok && !containsValue(kinds, QuickFix) {
+			provider["codeActionKinds"] = append(kinds, QuickFix)
+		}
+	default:
+		capabilities["codeActionProvider"] = CodeActionOptions{CodeActionKinds: []string{QuickFix}}
+	}
+
+	switch provider := capabilities["hoverProvider"].(type) {
+	case nil:
+		capabilities["hoverProvider"] = true
+	case bool:
+		if !provider {
+			capabilities["hoverProvider"] = true
+		}
+	}
+
+	raw, err := json.Marshal(capabilities)
+	if err != nil {
+		return nil, err
+	}
+	init["capabilities"] = raw
+	return init, nil
+}
+
+func containsValue(values []interface{}, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCodeActions appends the governance quick fixes to the wrapped
+// server's code actions and commands.
+func mergeCodeActions(result json.RawMessage, actions []CodeAction) (interface{}, error) {
+	var merged []interface{}
+	if err := json.Unmarshal(result, &merged); err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		merged = append(merged, action)
+	}
+	return merged, nil
+}
+
+// mergeHover appends the rule documentation to the wrapped server's hover.
+// Hovers in the deprecated MarkedString forms are left alone.
+func mergeHover(result json.RawMessage, hover *Hover) (interface{}, error) {
+	if string(result) == "null" || len(result) == 0 {
+		return hover, nil
+	}
+
+	var theirs struct {
+		Contents json.RawMessage `json:"contents"`
+		Range    *Range          `json:"range,omitempty"`
+	}
+	if err := json.Unmarshal(result, &theirs); err != nil {
+		return nil, err
+	}
+	var contents MarkupContent
+	if err := json.Unmarshal(theirs.Contents, &contents); err != nil || contents.Kind != Markdown {
+		return nil, nil
+	}
+
+	contents.Value += "\n\n---\n\n" + hover.Contents.Value
+	return Hover{Contents: contents, Range: theirs.Range}, nil
+}
+
+// publishOwn records the governance diagnostics for a document and
+// publishes them merged with the wrapped server's.
+func (p *Proxy) publishOwn(params PublishDiagnosticsParams) error {
+	raw := make([]json.RawMessage, 0, len(params.Diagnostics))
+	for _, d := range params.Diagnostics {
+		data, err := json.Marshal(d)
+		if err != nil {
+			return fmt.Errorf("failed to encode diagnostic: %w", err)
+		}
+		raw = append(raw, data)
+	}
+
+	p.mu.Lock()
+	p.ownDiagnostics[params.URI] = raw
+	p.mu.Unlock()
+	return p.publish(params.URI, params.Version)
+}
+
+func (p *Proxy) publish(uri string, version *int) error {
+	p.mu.Lock()
+	diagnostics := append(append([]json.RawMessage{}, p.childDiagnostics[uri]...), p.ownDiagnostics[uri]...)
+	if len(p.childDiagnostics[uri]) == 0 && len(p.ownDiagnostics[uri]) == 0 {
+		delete(p.childDiagnostics, uri)
+		delete(p.ownDiagnostics, uri)
+	}
+	p.mu.Unlock()
+
+	return p.client.Notify("textDocument/publishDiagnostics", publishParams{
+		URI:         uri,
+		Version:     version,
+		Diagnostics: diagnostics,
+	})
+}
@@ -0,0 +1,271 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestMain turns the test binary into a fake wrapped language server when
+// the proxy tests start it as their child process.
+func TestMain(m *testing.M) {
+	switch os.Getenv("MCPLSP_FAKE_LSP") {
+	case "serve":
+		fakeLanguageServer(os.Stdin, os.Stdout)
+		os.Exit(0)
+	case "crash":
+		os.Exit(2)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeLanguageServer answers like a minimal gopls: it publishes a compiler
+// diagnostic for every opened document and offers one code action and a
+// hover everywhere.
+func fakeLanguageServer(r io.Reader, w io.Writer) {
+	conn := NewConn(r, w)
+	for {
+		msg, err := conn.Read()
+		if err != nil {
+			return
+		}
+
+		switch msg.Method {
+		case "initialize":
+			conn.Reply(msg.ID, map[string]interface{}{
+				"capabilities": map[string]interface{}{
+					"hoverProvider":      true,
+					"codeActionProvider": map[string]interface{}{"codeActionKinds": []string{"source.organizeImports"}},
+					"definitionProvider": true,
+				},
+				"serverInfo": map[string]interface{}{"name": "fake"},
+			})
+		case "textDocument/didOpen":
+			var params DidOpenTextDocumentParams
+			json.Unmarshal(msg.Params, &params)
+			conn.Notify("textDocument/publishDiagnostics", map[string]interface{}{
+				"uri":     params.TextDocument.URI,
+				"version": params.TextDocument.Version,
+				"diagnostics": []interface{}{map[string]interface{}{
+					"range":    Range{Start: Position{Line: 4, Character: 1}, End: Position{Line: 4, Character: 9}},
+					"severity": SeverityError,
+					"source":   "compiler",
+					"message":  "undefined: read",
+					"tags":     []int{1},
+				}},
+			})
+		case "textDocument/codeAction":
+			conn.Reply(msg.ID, []interface{}{map[string]interface{}{"title": "Organize Imports", "kind": "source.organizeImports"}})
+		case "textDocument/hover":
+			conn.Reply(msg.ID, Hover{Contents: MarkupContent{Kind: Markdown, Value: "```go\nvar err error\n```"}})
+		case "textDocument/definition":
+			conn.Reply(msg.ID, []Location{{URI: "file:///work/demo/read.go"}})
+		case "shutdown":
+			conn.Reply(msg.ID, nil)
+		case "exit":
+			return
+		}
+	}
+}
+
+func TestProxy(t *testing.T) {
+	t.Setenv("MCPLSP_FAKE_LSP", "serve")
+
+	const uri = "file:///work/demo/load.go"
+	at := Position{Line: 3, Character: 8}
+	input := script(t,
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("initialized", map[string]interface{}{}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: uncheckedSrc},
+		}),
+		request(2, "textDocument/codeAction", CodeActionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Range:        Range{Start: at, End: at},
+		}),
+		request(3, "textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: at}),
+		request(4, "textDocument/definition", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: at}),
+		request(5, "shutdown", nil),
+		notification("exit", nil),
+	)
+
+	proxy := NewProxy(ProxyConfig{
+		Config:  Config{RuleIDs: []string{"error_handling"}},
+		Command: []string{os.Args[0]},
+	})
+	var out bytes.Buffer
+	if err := proxy.Serve(context.Background(), input, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	responses := make(map[string]*Message)
+	var published []publishParams
+	conn := NewConn(&out, nil)
+	for {
+		msg, err := conn.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if msg.IsResponse() {
+			responses[string(msg.ID)] = msg
+		}
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var params publishParams
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			published = append(published, params)
+		}
+	}
+
+	var init struct {
+		Capabilities struct {
+			CodeActionProvider CodeActionOptions `json:"codeActionProvider"`
+			DefinitionProvider bool              `json:"definitionProvider"`
+		} `json:"capabilities"`
+		ServerInfo ServerInfo `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(responses["1"].Result, &init); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := init.Capabilities.CodeActionProvider.CodeActionKinds; len(kinds) != 2 || kinds[1] != QuickFix {
+		t.Errorf("expected quickfix to be added to the code action kinds, got %v", kinds)
+	}
+	if !init.Capabilities.DefinitionProvider || init.ServerInfo.Name != "fake" {
+		t.Errorf("expected the wrapped server's capabilities to pass through, got %s", responses["1"].Result)
+	}
+
+	// Both the wrapped server's and the governance diagnostics reach the
+	// client, whichever arrives last
+	if len(published) == 0 {
+		t.Fatal("expected diagnostics to be published")
+	}
+	last := published[len(published)-1]
+	if last.URI != uri || len(last.Diagnostics) != 2 {
+		t.Fatalf("expected merged diagnostics, got %+v", last)
+	}
+	merged := string(last.Diagnostics[0]) + string(last.Diagnostics[1])
+	for _, want := range []string{`"source":"compiler"`, `"tags":[1]`, `"code":"error_handling"`} {
+		if !strings.Contains(merged, want) {
+			t.Errorf("merged diagnostics are missing %s: %s", want, merged)
+		}
+	}
+
+	var actions []CodeAction
+	if err := json.Unmarshal(responses["2"].Result, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || actions[0].Title != "Organize Imports" || actions[1].Kind != QuickFix {
+		t.Errorf("expected the governance quick fix after the wrapped server's action, got %s", responses["2"].Result)
+	}
+
+	var hover Hover
+	if err := json.Unmarshal(responses["3"].Result, &hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hover.Contents.Value, "```go\nvar err error\n```\n\n---\n\n**error_handling**") {
+		t.Errorf("expected the rule documentation below the wrapped server's hover, got %q", hover.Contents.Value)
+	}
+
+	if !strings.Contains(string(responses["4"].Result), "read.go") {
+		t.Errorf("expected the definition response to pass through, got %s", responses["4"].Result)
+	}
+	if responses["5"] == nil || responses["5"].Error != nil {
+		t.Errorf("unexpected shutdown response: %+v", responses["5"])
+	}
+}
+
+func TestProxyForwardsDuringAnalysis(t *testing.T) {
+	t.Setenv("MCPLSP_FAKE_LSP", "serve")
+
+	const uri = "file:///work/demo/load.go"
+	proxy := NewProxy(ProxyConfig{
+		Config:  Config{RuleIDs: []string{"error_handling"}},
+		Command: []string{os.Args[0]},
+	})
+
+	clientIn, input := io.Pipe()
+	output, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- proxy.Serve(context.Background(), clientIn, clientOut)
+		clientOut.Close()
+	}()
+	client := NewConn(output, input)
+
+	// Hold up the analysis of the opened document
+	proxy.server.analyzeMu.Lock()
+	at := Position{Line: 3, Character: 8}
+	for _, m := range []interface{}{
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: uncheckedSrc},
+		}),
+		request(2, "textDocument/codeAction", CodeActionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: Range{Start: at, End: at}}),
+		request(3, "textDocument/definition", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: at}),
+	} {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.WriteRaw(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The definition response arrives while the code action waits
+	for {
+		msg, err := client.Read()
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if string(msg.ID) == "2" {
+			t.Fatal("Expected the code action to wait for the analysis")
+		}
+		if string(msg.ID) == "3" {
+			break
+		}
+	}
+	proxy.server.analyzeMu.Unlock()
+
+	for {
+		msg, err := client.Read()
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if string(msg.ID) == "2" {
+			var actions []CodeAction
+			if err := json.Unmarshal(msg.Result, &actions); err != nil || len(actions) != 2 {
+				t.Errorf("Expected the governance quick fix once the analysis finished, got %s", msg.Result)
+			}
+			break
+		}
+	}
+
+	exit, _ := json.Marshal(notification("exit", nil))
+	client.WriteRaw(exit)
+	go io.Copy(io.Discard, output)
+	if err := <-done; err != nil {
+		t.Errorf("Serve: %v", err)
+	}
+}
+
+func TestProxyChildExit(t *testing.T) {
+	t.Setenv("MCPLSP_FAKE_LSP", "crash")
+
+	proxy := NewProxy(ProxyConfig{Command: []string{os.Args[0]}, Stderr: io.Discard})
+	r, w := io.Pipe()
+	defer w.Close()
+
+	err := proxy.Serve(context.Background(), r, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "exit status 2") {
+		t.Errorf("expected an error when the wrapped server exits, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package lsp
+
+import (
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+// QuickFixes returns the code actions that apply the suggested fixes of an
+// issue reported for doc. The fixes' offsets must refer to doc's text.
+// Refactorings are never preferred, so editors do not apply them unasked.
+func QuickFixes(doc *Document, issue ast.Issue) []CodeAction {
+	if len(issue.SuggestedFixes) == 0 {
+		return nil
+	}
+	diagnostic := Diagnostics(doc, []ast.Issue{issue})[0]
+
+	var actions []CodeAction
+	for _, fix := range issue.SuggestedFixes {
+		edits := make([]TextEdit, 0, len(fix.TextEdits))
+		for _, e := range fix.TextEdits {
+			if e.Pos.Offset > len(doc.Text) || e.End.Offset > len(doc.Text) {
+				return nil
+			}
+			edits = append(edits, TextEdit{
+				Range:   Range{Start: doc.PositionOf(e.Pos.Offset), End: doc.PositionOf(e.End.Offset)},
+				NewText: string(e.NewText),
+			})
+		}
+		actions = append(actions, CodeAction{
+			Title:       fix.Message,
+			Kind:        QuickFix,
+			Diagnostics: []Diagnostic{diagnostic},
+			IsPreferred: len(issue.SuggestedFixes) == 1 && !fix.Refactor,
+			Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{doc.URI: edits}},
+		})
+	}
+	return actions
+}
@@ -0,0 +1,261 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// applyEdits applies a code action's edits to the document text.
+func applyEdits(t *testing.T, doc *Document, action CodeAction) string {
+	t.Helper()
+	edits := action.Edit.Changes[doc.URI]
+	fixed := &Document{URI: doc.URI, Text: doc.Text}
+
+	// Apply from the end so earlier ranges stay valid
+	for i := len(edits) - 1; i >= 0; i-- {
+		r := edits[i].Range
+		if err := fixed.ApplyChanges([]TextDocumentContentChangeEvent{{Range: &r, Text: edits[i].NewText}}); err != nil {
+			t.Fatalf("ApplyChanges: %v", err)
+		}
+	}
+	return fixed.Text
+}
+
+func TestQuickFixes(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		src    string
+		title  string
+		want   string
+		absent bool
+	}{
+		{
+			name: "unchecked error",
+			rule: "error_handling",
+			src: `package demo
+
+import "os"
+
+func load(path string) ([]byte, int, error) {
+	data, err := os.ReadFile(path)
+	return data, len(data), nil
+}
+`,
+			title: "Check err and return it wrapped",
+			want: `package demo
+
+import (
+	"fmt"
+	"os"
+)
+
+func load(path string) ([]byte, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return data, len(data), nil
+}
+`,
+		},
+		{
+			name: "unchecked error without error result",
+			rule: "error_handling",
+			src: `package demo
+
+import "os"
+
+func load(path string) {
+	data, err := os.ReadFile(path)
+	_ = data
+}
+`,
+			absent: true,
+		},
+		{
+			name: "missing context",
+			rule: "api_design",
+			src: `package demo
+
+import (
+	"errors"
+)
+
+type Service struct{}
+
+func (s *Service) GetUser(id string) error {
+	return errors.New(id)
+}
+`,
+			title: "Add ctx context.Context as the first parameter of GetUser",
+			want: `package demo
+
+import (
+	"context"
+	"errors"
+)
+
+type Service struct{}
+
+func (s *Service) GetUser(ctx context.Context, id string) error {
+	return errors.New(id)
+}
+`,
+		},
+		{
+			name: "snake case function",
+			rule: "org_coding_standards",
+			src: `package demo
+
+func get_user_id() string {
+	return "id"
+}
+
+func name() string {
+	return get_user_id()
+}
+`,
+			title: "Rename get_user_id to getUserID",
+			want: `package demo
+
+func getUserID() string {
+	return "id"
+}
+
+func name() string {
+	return getUserID()
+}
+`,
+		},
+		{
+			name: "unprotected map",
+			rule: "concurrent_map_access",
+			src: `package demo
+
+type Cache struct {
+	items map[string]string
+}
+
+func (c *Cache) Fill() {
+	go func() {
+		c.items["a"] = "b"
+	}()
+}
+`,
+			title: "Add a sync.Mutex field to Cache",
+			want: `package demo
+
+import "sync"
+
+type Cache struct {
+	mu sync.Mutex
+
+	items map[string]string
+}
+
+func (c *Cache) Fill() {
+	go func() {
+		c.items["a"] = "b"
+	}()
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(Config{RuleIDs: []string{tt.rule}})
+			doc := &Document{URI: "file:///work/demo/demo.go", Text: tt.src}
+			issues, err := server.Analyze(doc)
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+
+			var actions []CodeAction
+			for _, issue := range issues {
+				actions = append(actions, QuickFixes(doc, issue)...)
+			}
+
+			if tt.absent {
+				if len(actions) != 0 {
+					t.Fatalf("expected no quick fixes, got %+v", actions)
+				}
+				return
+			}
+			if len(actions) == 0 {
+				t.Fatalf("expected a quick fix, issues: %+v", issues)
+			}
+
+			action := actions[0]
+			if action.Title != tt.title {
+				t.Errorf("title = %q, want %q", action.Title, tt.title)
+			}
+			if action.Kind != QuickFix || len(action.Diagnostics) != 1 || action.Diagnostics[0].Code != tt.rule {
+				t.Errorf("unexpected action metadata: %+v", action)
+			}
+			// Refactorings change declarations and are never preferred
+			if refactor := tt.rule == "api_design" || tt.rule == "org_coding_standards"; action.IsPreferred == refactor {
+				t.Errorf("IsPreferred = %v for a %s fix", action.IsPreferred, tt.rule)
+			}
+			if got := applyEdits(t, doc, action); got != tt.want {
+				t.Errorf("fixed source:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerCodeActions(t *testing.T) {
+	const uri = "file:///work/demo/load.go"
+	src := strings.Replace(uncheckedSrc, "func load() error", "func load() (int, error)", 1)
+	src = strings.Replace(src, "return nil", "return 0, nil", 1)
+	server := NewServer(Config{RuleIDs: []string{"error_handling"}})
+
+	messages := run(t, server, script(t,
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: src},
+		}),
+		request(2, "textDocument/codeAction", CodeActionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Range:        Range{Start: Position{Line: 3, Character: 5}, End: Position{Line: 3, Character: 5}},
+		}),
+		request(3, "textDocument/codeAction", CodeActionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Range:        Range{Start: Position{Line: 0}, End: Position{Line: 0, Character: 7}},
+		}),
+		request(4, "textDocument/codeAction", CodeActionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Range:        Range{Start: Position{Line: 3, Character: 5}, End: Position{Line: 3, Character: 5}},
+			Context:      CodeActionContext{Only: []string{"refactor"}},
+		}),
+		notification("exit", nil),
+	))
+
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(messages))
+	}
+
+	var init InitializeResult
+	if err := json.Unmarshal(messages[0].Result, &init); err != nil {
+		t.Fatal(err)
+	}
+	if init.Capabilities.CodeActionProvider == nil {
+		t.Errorf("expected the code action capability to be advertised: %+v", init.Capabilities)
+	}
+
+	var actions []CodeAction
+	if err := json.Unmarshal(messages[2].Result, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || !strings.Contains(applyEdits(t, &Document{URI: uri, Text: src}, actions[0]), "return 0, fmt.Errorf") {
+		t.Errorf("unexpected code actions for the flagged line: %s", messages[2].Result)
+	}
+
+	for _, msg := range messages[3:] {
+		if string(msg.Result) != "[]" {
+			t.Errorf("expected no code actions, got %s", msg.Result)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"io"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+// Config configures the language server.
+type Config struct {
+	// Registry supplies the YAML rules; it may be nil to run only the
+	// registered analyses.
+	Registry *rules.RuleRegistry
+	// RuleIDs selects the rules to check; by default every rule in the
+	// registry, or every registered analysis without one.
+	RuleIDs []string
+	// TypeCheck enables type-aware analysis of each buffer.
+	TypeCheck bool
+	// TemplatesDir holds the code templates that hovers link to; hovers
+	// carry no template link when it is empty.
+	TemplatesDir string
+	// Debounce delays the analysis of a changed buffer until it has not
+	// changed for this long; 250ms by default.
+	Debounce time.Duration
+}
+
+const defaultDebounce = 250 * time.Millisecond
+
+// Server is a language server that analyzes open Go buffers with the
+// analyzer engine and publishes the findings as diagnostics. Buffers are
+// analyzed in the background, one at a time, so that reading the client's
+// messages never waits for an analysis.
+type Server struct {
+	config Config
+	conn   *Conn
+	// publish delivers diagnostics; by default they are sent to the client
+	publish func(params PublishDiagnosticsParams) error
+
+	// engine holds the compiled rule checks and the importer shared by
+	// every analysis; analyzeMu serializes the analyses using it
+	engine    *analyzer.AnalyzerEngine
+	engineErr error
+	analyzeMu sync.Mutex
+
+	mu          sync.Mutex
+	docs        map[string]*Document
+	issues      map[string][]ast.Issue
+	analyzed    map[string]Document // the buffers the issues were computed for
+	pending     map[string]*analysis
+	initialized bool
+	shutdown    bool
+}
+
+// analysis is a scheduled or running analysis of a document.
+type analysis struct {
+	timer *time.Timer
+	done  chan struct{} // closed once the analysis has finished
+}
+
+func NewServer(config Config) *Server {
+	if len(config.RuleIDs) == 0 {
+		if config.Registry != nil {
+			config.RuleIDs = config.Registry.IDs()
+		} else {
+			for _, rule := range analyzer.Rules() {
+				config.RuleIDs = append(config.RuleIDs, rule.ID())
+			}
+		}
+	}
+	if config.Debounce <= 0 {
+		config.Debounce = defaultDebounce
+	}
+	engine, err := analyzer.NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: config.TypeCheck}, config.Registry)
+	return &Server{
+		config:    config,
+		engine:    engine,
+		engineErr: err,
+		docs:      make(map[string]*Document),
+		issues:    make(map[string][]ast.Issue),
+		analyzed:  make(map[string]Document),
+		pending:   make(map[string]*analysis),
+	}
+}
+
+// Serve runs the server over a pair of streams, typically stdin and stdout,
+// until the client sends exit, the input ends or ctx is cancelled. Pending
+// analyses are abandoned when it returns.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = NewConn(r, w)
+	defer s.stop()
+
+	// Read on a goroutine so that cancellation is not held up by a read
+	// blocked on a client that sends nothing
+	done := make(chan struct{})
+	defer close(done)
+	messages := make(chan *Message)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := s.conn.Read()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		var msg *Message
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case msg = <-messages:
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *Message) error {
+	if msg.IsResponse() {
+		return nil
+	}
+
+	result, err := s.dispatch(msg)
+	if msg.IsNotification() {
+		if err != nil {
+			log.Printf("lsp: %s: %v", msg.Method, err)
+		}
+		return nil
+	}
+
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			return s.conn.ReplyError(msg.ID, rerr.Code, rerr.Message)
+		}
+		return s.conn.ReplyError(msg.ID, CodeInternalError, err.Error())
+	}
+	return s.conn.Reply(msg.ID, result)
+}
+
+func (s *Server) dispatch(msg *Message) (interface{}, error) {
+	if msg.Method == "initialize" {
+		s.mu.Lock()
+		s.initialized = true
+		s.mu.Unlock()
+		return s.initializeResult(), nil
+	}
+
+	s.mu.Lock()
+	initialized, shutdown := s.initialized, s.shutdown
+	s.mu.Unlock()
+	if !initialized {
+		return nil, &ResponseError{Code: CodeServerNotInitialized, Message: "server not initialized"}
+	}
+	if shutdown && msg.IsRequest() {
+		return nil, &ResponseError{Code: CodeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch msg.Method {
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		// Publish the diagnostics of the last changes before replying
+		s.flush()
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		doc := &Document{
+			URI:     params.TextDocument.URI,
+			Version: params.TextDocument.Version,
+			Text:    params.TextDocument.Text,
+		}
+		s.mu.Lock()
+		s.docs[doc.URI] = doc
+		s.mu.Unlock()
+		s.schedule(doc.URI, 0)
+		return nil, nil
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("document not open: %s", params.TextDocument.URI)
+		}
+		err := doc.ApplyChanges(params.ContentChanges)
+		doc.Version = params.TextDocument.Version
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		s.schedule(doc.URI, s.config.Debounce)
+		return nil, nil
+
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if doc, ok := s.docs[params.TextDocument.URI]; ok && params.Text != nil {
+			doc.Text = *params.Text
+		}
+		s.mu.Unlock()
+		s.schedule(params.TextDocument.URI, 0)
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		delete(s.issues, params.TextDocument.URI)
+		delete(s.analyzed, params.TextDocument.URI)
+		if a := s.pending[params.TextDocument.URI]; a != nil && a.timer.Stop() {
+			delete(s.pending, params.TextDocument.URI)
+			close(a.done)
+		}
+		s.mu.Unlock()
+		return nil, s.publishDiagnostics(PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/codeAction":
+		var params CodeActionParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params), nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	}
+
+	return nil, &ResponseError{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+}
+
+func (s *Server) initializeResult() *InitializeResult {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: &TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    SyncIncremental,
+				Save:      &SaveOptions{IncludeText: true},
+			},
+			CodeActionProvider: &CodeActionOptions{
+				CodeActionKinds: []string{QuickFix},
+			},
+			HoverProvider: true,
+		},
+		ServerInfo: &ServerInfo{Name: Source},
+	}
+}
+
+// schedule analyzes the document after delay, replacing an analysis of it
+// that has not started yet.
+func (s *Server) schedule(uri string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a := s.pending[uri]; a != nil && a.timer.Stop() {
+		a.timer.Reset(delay)
+		return
+	}
+	a := &analysis{done: make(chan struct{})}
+	a.timer = time.AfterFunc(delay, func() { s.run(uri, a) })
+	s.pending[uri] = a
+}
+
+func (s *Server) run(uri string, a *analysis) {
+	defer close(a.done)
+
+	if err := s.analyze(uri); err != nil {
+		log.Printf("lsp: analysis of %s: %v", uri, err)
+	}
+
+	s.mu.Lock()
+	if s.pending[uri] == a {
+		delete(s.pending, uri)
+	}
+	s.mu.Unlock()
+}
+
+// wait starts the pending analysis of the document without further delay
+// and waits for it to finish.
+func (s *Server) wait(uri string) {
+	s.mu.Lock()
+	a := s.pending[uri]
+	if a != nil && a.timer.Stop() {
+		a.timer.Reset(0)
+	}
+	s.mu.Unlock()
+
+	if a != nil {
+		<-a.done
+	}
+}
+
+// flush waits for the pending analyses of every document.
+func (s *Server) flush() {
+	s.mu.Lock()
+	uris := make([]string, 0, len(s.pending))
+	for uri := range s.pending {
+		uris = append(uris, uri)
+	}
+	s.mu.Unlock()
+
+	for _, uri := range uris {
+		s.wait(uri)
+	}
+}
+
+// stop cancels the analyses that have not started and waits for the
+// running ones.
+func (s *Server) stop() {
+	s.mu.Lock()
+	var running []*analysis
+	for uri, a := range s.pending {
+		if a.timer.Stop() {
+			delete(s.pending, uri)
+			close(a.done)
+		} else {
+			running = append(running, a)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, a := range running {
+		<-a.done
+	}
+}
+
+// analyze runs the engine over the open buffer and publishes the findings.
+// While the buffer does not parse, the previous diagnostics stay in place;
+// other failures, such as an invalid rule registry, are logged to the
+// client. Findings for a buffer that has changed during the analysis are
+// dropped, since a newer analysis follows.
+func (s *Server) analyze(uri string) error {
+	s.mu.Lock()
+	doc, ok := s.docs[uri]
+	var snapshot Document
+	if ok {
+		snapshot = *doc
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	issues, err := s.Analyze(&snapshot)
+	if err != nil {
+		var syntax scanner.ErrorList
+		if errors.As(err, &syntax) {
+			return nil
+		}
+		return s.logMessage(MessageError, fmt.Sprintf("Analysis of %s failed: %v", uri, err))
+	}
+
+	s.mu.Lock()
+	doc, ok = s.docs[uri]
+	if !ok || doc.Version != snapshot.Version || doc.Text != snapshot.Text {
+		s.mu.Unlock()
+		return nil
+	}
+	s.issues[uri] = issues
+	s.analyzed[uri] = snapshot
+	s.mu.Unlock()
+
+	version := snapshot.Version
+	return s.publishDiagnostics(PublishDiagnosticsParams{
+		URI:         uri,
+		Version:     &version,
+		Diagnostics: Diagnostics(&snapshot, issues),
+	})
+}
+
+// logMessage asks the client to log a message, typically in its output
+// panel for the server.
+func (s *Server) logMessage(typ int, message string) error {
+	return s.conn.Notify("window/logMessage", LogMessageParams{Type: typ, Message: message})
+}
+
+func (s *Server) publishDiagnostics(params PublishDiagnosticsParams) error {
+	if s.publish != nil {
+		return s.publish(params)
+	}
+	return s.conn.Notify("textDocument/publishDiagnostics", params)
+}
+
+// codeActions returns the quick fixes for the findings within the requested
+// range. Fixes are only offered while the buffer still matches the text the
+// findings were computed for, since their edits would not apply otherwise.
+func (s *Server) codeActions(params CodeActionParams) []CodeAction {
+	actions := []CodeAction{}
+	if len(params.Context.Only) > 0 && !slices.Contains(params.Context.Only, QuickFix) {
+		return actions
+	}
+
+	snapshot, issues, ok := s.current(params.TextDocument.URI)
+	if !ok {
+		return actions
+	}
+
+	for _, issue := range issues {
+		if !overlaps(IssueRange(&snapshot, issue), params.Range) {
+			continue
+		}
+		actions = append(actions, QuickFixes(&snapshot, issue)...)
+	}
+	return actions
+}
+
+// current returns the findings for uri together with the buffer they were
+// computed for, provided the buffer has not changed since. It waits for a
+// pending analysis of the buffer first.
+func (s *Server) current(uri string) (Document, []ast.Issue, bool) {
+	s.wait(uri)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[uri]
+	snapshot, analyzed := s.analyzed[uri]
+	if !ok || !analyzed || doc.Version != snapshot.Version || doc.Text != snapshot.Text {
+		return Document{}, nil, false
+	}
+	return snapshot, s.issues[uri], true
+}
+
+func overlaps(a, b Range) bool {
+	return !before(a.End, b.Start) && !before(b.End, a.Start)
+}
+
+func before(a, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
+
+// Analyze runs the configured rules over a document's current text.
+func (s *Server) Analyze(doc *Document) ([]ast.Issue, error) {
+	if s.engineErr != nil {
+		return nil, s.engineErr
+	}
+
+	s.analyzeMu.Lock()
+	defer s.analyzeMu.Unlock()
+
+	result, err := s.engine.Fork().Analyze(doc.Path(), []byte(doc.Text), s.config.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only report findings for this buffer
+	var issues []ast.Issue
+	for _, issue := range result.Issues {
+		if issue.Position.Filename == "" || issue.Position.Filename == doc.Path() {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+func decode(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+// script frames the messages as client input.
+func script(t *testing.T, messages ...interface{}) io.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	conn := NewConn(nil, &buf)
+	for _, m := range messages {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteRaw(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &buf
+}
+
+func request(id int, method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+}
+
+func notification(method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+}
+
+// run serves the scripted input and returns every message the server sent.
+func run(t *testing.T, server *Server, input io.Reader) []*Message {
+	t.Helper()
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), input, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	var messages []*Message
+	conn := NewConn(&out, nil)
+	for {
+		msg, err := conn.Read()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+const uncheckedSrc = `package demo
+
+func load() error {
+	data, err := read()
+	_ = data
+	return nil
+}
+`
+
+const checkedSrc = `package demo
+
+func load() error {
+	data, err := read()
+	if err != nil {
+		return err
+	}
+	_ = data
+	return nil
+}
+`
+
+func TestServerPublishesDiagnostics(t *testing.T) {
+	const uri = "file:///work/demo/load.go"
+	// Changes are only analyzed once typing pauses, or before shutdown
+	server := NewServer(Config{RuleIDs: []string{"error_handling"}, Debounce: time.Minute})
+
+	messages := run(t, server, script(t,
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("initialized", map[string]interface{}{}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: uri, LanguageID: "go", Version: 1, Text: uncheckedSrc},
+		}),
+		// Waits for the analysis of the opened buffer
+		request(2, "textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: uncheckedSrc + "\n"}},
+		}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 3},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: checkedSrc}},
+		}),
+		request(3, "textDocument/unknown", map[string]interface{}{}),
+		request(4, "shutdown", nil),
+		notification("exit", nil),
+	))
+
+	if len(messages) != 6 {
+		t.Fatalf("expected 6 messages, got %d", len(messages))
+	}
+
+	var init InitializeResult
+	if err := json.Unmarshal(messages[0].Result, &init); err != nil {
+		t.Fatal(err)
+	}
+	if init.Capabilities.TextDocumentSync == nil || init.Capabilities.TextDocumentSync.Change != SyncIncremental {
+		t.Errorf("unexpected capabilities: %+v", init.Capabilities)
+	}
+
+	var opened PublishDiagnosticsParams
+	if err := json.Unmarshal(messages[1].Params, &opened); err != nil {
+		t.Fatal(err)
+	}
+	if messages[1].Method != "textDocument/publishDiagnostics" || len(opened.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic after open, got %s", messages[1].Params)
+	}
+	d := opened.Diagnostics[0]
+	wantRange := Range{Start: Position{Line: 3, Character: 1}, End: Position{Line: 3, Character: 20}}
+	if d.Code != "error_handling" || d.Severity != SeverityWarning || d.Source != Source || d.Range != wantRange {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+
+	if messages[2].Error != nil || string(messages[2].Result) != "null" {
+		t.Errorf("unexpected hover response: %+v", messages[2])
+	}
+	if messages[3].Error == nil || messages[3].Error.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found, got %+v", messages[3])
+	}
+
+	// Only the last of the changes is analyzed
+	var changed PublishDiagnosticsParams
+	if err := json.Unmarshal(messages[4].Params, &changed); err != nil {
+		t.Fatal(err)
+	}
+	if messages[4].Method != "textDocument/publishDiagnostics" || len(changed.Diagnostics) != 0 || changed.Version == nil || *changed.Version != 3 {
+		t.Errorf("expected diagnostics to clear at version 3, got %s", messages[4].Params)
+	}
+	if messages[5].Error != nil || string(messages[5].Result) != "null" {
+		t.Errorf("unexpected shutdown response: %+v", messages[5])
+	}
+}
+
+func TestServerLogsAnalysisFailures(t *testing.T) {
+	registry, err := rules.NewRuleRegistry(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(Config{Registry: registry, RuleIDs: []string{"no_such_rule"}})
+
+	messages := run(t, server, script(t,
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("initialized", map[string]interface{}{}),
+		// A buffer that does not parse is not worth a message
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: "file:///work/demo/broken.go", LanguageID: "go", Version: 1, Text: "package demo\n\nfunc {"},
+		}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{
+			TextDocument: TextDocumentItem{URI: "file:///work/demo/load.go", LanguageID: "go", Version: 1, Text: checkedSrc},
+		}),
+		request(2, "shutdown", nil),
+		notification("exit", nil),
+	))
+
+	var logged []LogMessageParams
+	for _, msg := range messages {
+		if msg.Method == "textDocument/publishDiagnostics" {
+			t.Errorf("unexpected diagnostics: %s", msg.Params)
+		}
+		if msg.Method != "window/logMessage" {
+			continue
+		}
+		var params LogMessageParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatal(err)
+		}
+		logged = append(logged, params)
+	}
+	if len(logged) != 1 || logged[0].Type != MessageError || !strings.Contains(logged[0].Message, "unknown rule: no_such_rule") {
+		t.Errorf("expected the failed analysis of load.go to be logged, got %+v", logged)
+	}
+}
+
+func TestServerCancel(t *testing.T) {
+	// The client never writes, so only cancellation can end the session
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewServer(Config{}).Serve(ctx, r, io.Discard) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+}
+
+func TestServerRequiresInitialize(t *testing.T) {
+	messages := run(t, NewServer(Config{}), script(t, request(1, "shutdown", nil)))
+	if len(messages) != 1 || messages[0].Error == nil || messages[0].Error.Code != CodeServerNotInitialized {
+		t.Errorf("expected a not-initialized error, got %+v", messages)
+	}
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleReporter writes the Checkstyle XML format understood by most CI
+// annotation tools. The rule id is the error source.
+type checkstyleReporter struct{}
+
+func (checkstyleReporter) Report(w io.Writer, result *Result) error {
+	report := checkstyleReport{Version: "5.0"}
+
+	for _, f := range result.Files {
+		file := checkstyleFile{Name: f.Path}
+		if f.Err != nil {
+			file.Errors = append(file.Errors, checkstyleError{
+				Severity: "error",
+				Message:  f.Err.Error(),
+				Source:   ToolName,
+			})
+		}
+		for _, issue := range f.Issues {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     issue.Position.Line,
+				Column:   issue.Position.Column,
+				Severity: checkstyleSeverity(issue.Severity),
+				Message:  issue.Description,
+				Source:   issue.RuleID,
+			})
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write Checkstyle report: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write Checkstyle report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func checkstyleSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "error":
+		return "error"
+	case "info":
+		return "info"
+	}
+	return "warning"
+}
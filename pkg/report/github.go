@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// githubReporter writes GitHub Actions workflow commands, which the runner
+// turns into annotations on the pull request diff.
+type githubReporter struct{}
+
+func (githubReporter) Report(w io.Writer, result *Result) error {
+	for _, f := range result.Files {
+		if f.Err != nil {
+			if _, err := fmt.Fprintf(w, "::error file=%s,title=%s::%s\n",
+				escapeProperty(f.Path), ToolName, escapeData(f.Err.Error())); err != nil {
+				return err
+			}
+		}
+		for _, issue := range f.Issues {
+			if _, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=%s::%s\n",
+				githubLevel(issue.Severity),
+				escapeProperty(f.Path),
+				issue.Position.Line,
+				issue.Position.Column,
+				escapeProperty(issue.RuleID),
+				escapeData(issue.Description)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func githubLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "error":
+		return "error"
+	case "info":
+		return "notice"
+	}
+	return "warning"
+}
+
+var (
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeData(s string) string     { return dataEscaper.Replace(s) }
+func escapeProperty(s string) string { return propertyEscaper.Replace(s) }
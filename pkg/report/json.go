@@ -0,0 +1,74 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type jsonReport struct {
+	Passed  bool        `json:"passed"`
+	Summary jsonSummary `json:"summary"`
+	Files   []jsonFile  `json:"files"`
+}
+
+type jsonSummary struct {
+	Files       int `json:"files"`
+	FailedFiles int `json:"failedFiles"`
+	Issues      int `json:"issues"`
+	Errors      int `json:"errors"`
+}
+
+type jsonFile struct {
+	Path   string      `json:"path"`
+	Issues []jsonIssue `json:"issues"`
+	Error  string      `json:"error,omitempty"`
+}
+
+type jsonIssue struct {
+	RuleID      string `json:"ruleId"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+}
+
+// jsonReporter writes the result as a single JSON document.
+type jsonReporter struct{}
+
+func (jsonReporter) Report(w io.Writer, result *Result) error {
+	report := jsonReport{
+		Passed: result.Passed(),
+		Files:  make([]jsonFile, 0, len(result.Files)),
+	}
+	report.Summary.Files = len(result.Files)
+
+	for _, f := range result.Files {
+		file := jsonFile{Path: f.Path, Issues: make([]jsonIssue, 0, len(f.Issues))}
+		if f.Err != nil {
+			file.Error = f.Err.Error()
+			report.Summary.Errors++
+		}
+		if len(f.Issues) > 0 {
+			report.Summary.FailedFiles++
+		}
+		for _, issue := range f.Issues {
+			file.Issues = append(file.Issues, jsonIssue{
+				RuleID:      issue.RuleID,
+				Description: issue.Description,
+				Severity:    issue.Severity,
+				Line:        issue.Position.Line,
+				Column:      issue.Position.Column,
+			})
+		}
+		report.Summary.Issues += len(f.Issues)
+		report.Files = append(report.Files, file)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitReporter writes one test suite per file. Every issue is a failed test
+// case and a file without issues is a single passing one, so dashboards can
+// track files going green.
+type junitReporter struct{}
+
+func (junitReporter) Report(w io.Writer, result *Result) error {
+	suites := junitTestSuites{Name: ToolName}
+
+	for _, f := range result.Files {
+		suite := junitTestSuite{Name: f.Path}
+
+		switch {
+		case f.Err != nil:
+			suite.Errors = 1
+			suite.TestCases = []junitTestCase{{
+				Name:      "validate",
+				ClassName: f.Path,
+				Error:     &junitMessage{Message: f.Err.Error(), Type: "error", Body: f.Err.Error()},
+			}}
+		case len(f.Issues) == 0:
+			suite.TestCases = []junitTestCase{{Name: "validate", ClassName: f.Path}}
+		default:
+			for _, issue := range f.Issues {
+				suite.TestCases = append(suite.TestCases, junitTestCase{
+					Name:      fmt.Sprintf("%s:%d:%d", issue.RuleID, issue.Position.Line, issue.Position.Column),
+					ClassName: f.Path,
+					Failure: &junitMessage{
+						Message: issue.Description,
+						Type:    issue.Severity,
+						Body: fmt.Sprintf("%s:%d:%d: [%s] %s",
+							f.Path, issue.Position.Line, issue.Position.Column, issue.RuleID, issue.Description),
+					},
+				})
+			}
+			suite.Failures = len(f.Issues)
+		}
+
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+// FileResult is the outcome of validating one file. Err is set when the file
+// could not be validated at all.
+type FileResult struct {
+	Path   string
+	Issues []ast.Issue
+	Err    error
+}
+
+// Result is the outcome of a validation run.
+type Result struct {
+	Files []FileResult
+	// Rules holds the YAML metadata of the known rules, used by formats that
+	// describe rules alongside findings.
+	Rules []*rules.Rule
+}
+
+// Issues returns the issues of all files in order.
+func (r *Result) Issues() []ast.Issue {
+	var issues []ast.Issue
+	for _, f := range r.Files {
+		issues = append(issues, f.Issues...)
+	}
+	return issues
+}
+
+// Passed reports whether every file was validated without issues.
+func (r *Result) Passed() bool {
+	for _, f := range r.Files {
+		if f.Err != nil || len(f.Issues) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reporter renders a validation result in one output format.
+type Reporter interface {
+	Report(w io.Writer, result *Result) error
+}
+
+var reporters = map[string]Reporter{
+	"text":           textReporter{},
+	"json":           jsonReporter{},
+	"junit-xml":      junitReporter{},
+	"checkstyle":     checkstyleReporter{},
+	"github-actions": githubReporter{},
+	"sarif":          sarifReporter{},
+}
+
+// New returns the reporter for a format name.
+func New(format string) (Reporter, error) {
+	reporter, ok := reporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q (want one of %s)", format, strings.Join(Formats(), ", "))
+	}
+	return reporter, nil
+}
+
+// Formats returns the supported format names.
+func Formats() []string {
+	formats := make([]string, 0, len(reporters))
+	for format := range reporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Write renders result in format to the file at path, or to stdout when path
+// is empty or "-".
+func Write(path, format string, result *Result) error {
+	reporter, err := New(format)
+	if err != nil {
+		return err
+	}
+
+	if ToStdout(path) {
+		return reporter.Report(os.Stdout, result)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := reporter.Report(f, result); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// ToStdout reports whether a report written to path goes to stdout.
+func ToStdout(path string) bool {
+	return path == "" || path == "-"
+}
+
+type sarifReporter struct{}
+
+func (sarifReporter) Report(w io.Writer, result *Result) error {
+	return WriteSARIF(w, result.Issues(), result.Rules)
+}
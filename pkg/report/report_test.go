@@ -0,0 +1,90 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+func sampleResult() *Result {
+	return &Result{
+		Files: []FileResult{
+			{
+				Path: "pkg/a.go",
+				Issues: []ast.Issue{{
+					RuleID:      "error_handling",
+					Description: "Missing error check, see docs: 100%",
+					Severity:    "warning",
+					Position:    token.Position{Filename: "pkg/a.go", Line: 3, Column: 2},
+				}},
+			},
+			{Path: "pkg/b.go"},
+			{Path: "pkg/c.go", Err: errors.New("failed to parse file")},
+		},
+	}
+}
+
+func TestReporters(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{
+			"pkg/a.go\n  - [error_handling] Missing error check, see docs: 100% (Line 3, Col 2) - warning",
+			"pkg/c.go\n  error: failed to parse file",
+			"Validation failed: 1 issue(s) in 1 of 3 file(s), 1 file(s) could not be validated",
+		}},
+		{"json", []string{`"passed": false`, `"ruleId": "error_handling"`, `"error": "failed to parse file"`}},
+		{"junit-xml", []string{`<testsuites name="go-mcp-lsp" tests="3" failures="1" errors="1">`, `<failure message="Missing error check`}},
+		{"checkstyle", []string{`<file name="pkg/a.go">`, `source="error_handling"`, `severity="warning"`}},
+		{"github-actions", []string{
+			"::warning file=pkg/a.go,line=3,col=2,title=error_handling::Missing error check, see docs: 100%25",
+			"::error file=pkg/c.go,title=go-mcp-lsp::failed to parse file",
+		}},
+		{"sarif", []string{`"ruleId": "error_handling"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			reporter, err := New(tt.format)
+			if err != nil {
+				t.Fatalf("New(%q): %v", tt.format, err)
+			}
+
+			var buf bytes.Buffer
+			if err := reporter.Report(&buf, sampleResult()); err != nil {
+				t.Fatalf("Report: %v", err)
+			}
+			out := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+
+			switch tt.format {
+			case "json", "sarif":
+				if !json.Valid(buf.Bytes()) {
+					t.Errorf("invalid JSON:\n%s", out)
+				}
+			case "junit-xml", "checkstyle":
+				if err := xml.Unmarshal(buf.Bytes(), new(struct{ XMLName xml.Name })); err != nil {
+					t.Errorf("invalid XML: %v\n%s", err, out)
+				}
+			}
+		})
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("yaml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,204 @@
+// Package report renders validation results for people and tools.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// ToolName identifies this project in generated reports.
+	ToolName = "go-mcp-lsp"
+)
+
+// SARIFLog is the root object of a SARIF 2.1.0 log.
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name  string                `json:"name"`
+	Rules []SARIFRuleDescriptor `json:"rules"`
+}
+
+type SARIFRuleDescriptor struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *SARIFMessage       `json:"shortDescription,omitempty"`
+	FullDescription      *SARIFMessage       `json:"fullDescription,omitempty"`
+	Help                 *SARIFMessage       `json:"help,omitempty"`
+	DefaultConfiguration *SARIFConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           *SARIFProperties    `json:"properties,omitempty"`
+}
+
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SARIFProperties struct {
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// NewSARIFLog converts issues into a single-run SARIF log. Every rule in
+// declared becomes a rule descriptor carrying its YAML description, rationale and
+// category; rules that only appear in issues get a bare descriptor.
+func NewSARIFLog(issues []ast.Issue, declared []*rules.Rule) *SARIFLog {
+	var descriptors []SARIFRuleDescriptor
+	index := make(map[string]int)
+
+	sorted := append([]*rules.Rule(nil), declared...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+	for _, rule := range sorted {
+		if _, ok := index[rule.ID]; ok {
+			continue
+		}
+		index[rule.ID] = len(descriptors)
+		descriptors = append(descriptors, ruleDescriptor(rule))
+	}
+
+	results := make([]SARIFResult, 0, len(issues))
+	for _, issue := range issues {
+		i, ok := index[issue.RuleID]
+		if !ok {
+			i = len(descriptors)
+			index[issue.RuleID] = i
+			descriptors = append(descriptors, SARIFRuleDescriptor{ID: issue.RuleID})
+		}
+
+		result := SARIFResult{
+			RuleID:    issue.RuleID,
+			RuleIndex: i,
+			Level:     sarifLevel(issue.Severity),
+			Message:   SARIFMessage{Text: issue.Description},
+		}
+		if issue.Position.Filename != "" {
+			location := SARIFLocation{
+				PhysicalLocation: SARIFPhysicalLocation{
+					ArtifactLocation: SARIFArtifactLocation{URI: artifactURI(issue.Position.Filename)},
+				},
+			}
+			if issue.Position.Line > 0 {
+				location.PhysicalLocation.Region = &SARIFRegion{
+					StartLine:   issue.Position.Line,
+					StartColumn: issue.Position.Column,
+				}
+			}
+			result.Locations = []SARIFLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	return &SARIFLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{
+				Driver: SARIFDriver{Name: ToolName, Rules: descriptors},
+			},
+			Results: results,
+		}},
+	}
+}
+
+// WriteSARIF writes issues as an indented SARIF 2.1.0 log.
+func WriteSARIF(w io.Writer, issues []ast.Issue, rules []*rules.Rule) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(NewSARIFLog(issues, rules)); err != nil {
+		return fmt.Errorf("failed to write SARIF log: %w", err)
+	}
+	return nil
+}
+
+func ruleDescriptor(rule *rules.Rule) SARIFRuleDescriptor {
+	d := SARIFRuleDescriptor{ID: rule.ID}
+	if rule.Description != "" {
+		d.ShortDescription = &SARIFMessage{Text: rule.Description}
+	}
+	if rule.Rationale != "" {
+		d.FullDescription = &SARIFMessage{Text: rule.Rationale}
+		d.Help = &SARIFMessage{Text: rule.Rationale}
+	}
+	if rule.Severity != "" {
+		d.DefaultConfiguration = &SARIFConfiguration{Level: sarifLevel(rule.Severity)}
+	}
+	if rule.Category != "" {
+		d.Properties = &SARIFProperties{Category: rule.Category, Tags: []string{rule.Category}}
+	}
+	return d
+}
+
+// sarifLevel maps rule severities onto SARIF result levels. Unknown
+// severities get SARIF's default level, warning.
+func sarifLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "error":
+		return "error"
+	case "info":
+		return "note"
+	}
+	return "warning"
+}
+
+// artifactURI returns a relative URI for relative paths, so results resolve
+// against the checkout root, and a file URI for absolute ones.
+func artifactURI(path string) string {
+	uri := filepath.ToSlash(filepath.Clean(path))
+	if filepath.IsAbs(path) {
+		return "file://" + uri
+	}
+	return strings.TrimPrefix(uri, "./")
+}
@@ -0,0 +1,86 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	rules := []*rules.Rule{
+		{
+			ID:          "error_handling",
+			Description: "Ensures proper error handling",
+			Rationale:   "Unchecked errors hide failures",
+			Category:    "code_quality",
+			Severity:    "warning",
+		},
+	}
+	issues := []ast.Issue{
+		{
+			RuleID:      "error_handling",
+			Description: "Missing error check",
+			Severity:    "warning",
+			Position:    token.Position{Filename: "./pkg/a.go", Line: 3, Column: 2},
+		},
+		{
+			RuleID:      "custom_rule",
+			Description: "Custom finding",
+			Severity:    "info",
+			Position:    token.Position{Filename: "pkg/b.go"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, issues, rules); err != nil {
+		t.Fatalf("WriteSARIF: %v", err)
+	}
+
+	var log SARIFLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log header: version %q, %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("expected 2 rule descriptors, got %d", len(run.Tool.Driver.Rules))
+	}
+	rule := run.Tool.Driver.Rules[0]
+	if rule.ID != "error_handling" || rule.FullDescription == nil || rule.FullDescription.Text != "Unchecked errors hide failures" {
+		t.Errorf("rule metadata not mapped: %+v", rule)
+	}
+	if rule.Properties == nil || rule.Properties.Category != "code_quality" {
+		t.Errorf("rule category not mapped: %+v", rule.Properties)
+	}
+
+	tests := []struct {
+		ruleIndex int
+		level     string
+		uri       string
+		hasRegion bool
+	}{
+		{0, "warning", "pkg/a.go", true},
+		{1, "note", "pkg/b.go", false},
+	}
+	for i, tt := range tests {
+		result := run.Results[i]
+		if result.RuleIndex != tt.ruleIndex || result.Level != tt.level {
+			t.Errorf("result %d: got index %d level %q, want %d %q", i, result.RuleIndex, result.Level, tt.ruleIndex, tt.level)
+		}
+		location := result.Locations[0].PhysicalLocation
+		if location.ArtifactLocation.URI != tt.uri {
+			t.Errorf("result %d: got uri %q, want %q", i, location.ArtifactLocation.URI, tt.uri)
+		}
+		if (location.Region != nil) != tt.hasRegion {
+			t.Errorf("result %d: got region %+v", i, location.Region)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// textReporter prints findings grouped by file, followed by a summary line.
+type textReporter struct{}
+
+func (textReporter) Report(w io.Writer, result *Result) error {
+	failedFiles, issueCount, errorCount := 0, 0, 0
+
+	for _, f := range result.Files {
+		if f.Err != nil {
+			errorCount++
+			fmt.Fprintf(w, "%s\n  error: %v\n", f.Path, f.Err)
+			continue
+		}
+		if len(f.Issues) == 0 {
+			continue
+		}
+
+		failedFiles++
+		issueCount += len(f.Issues)
+		fmt.Fprintln(w, f.Path)
+		for _, issue := range f.Issues {
+			fmt.Fprintf(w, "  - [%s] %s (Line %d, Col %d) - %s\n",
+				issue.RuleID,
+				issue.Description,
+				issue.Position.Line,
+				issue.Position.Column,
+				issue.Severity)
+		}
+	}
+
+	if failedFiles == 0 && errorCount == 0 {
+		_, err := fmt.Fprintf(w, "Validation passed! (%d file(s))\n", len(result.Files))
+		return err
+	}
+
+	fmt.Fprintf(w, "Validation failed: %d issue(s) in %d of %d file(s)", issueCount, failedFiles, len(result.Files))
+	if errorCount > 0 {
+		fmt.Fprintf(w, ", %d file(s) could not be validated", errorCount)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,232 @@
+package rules
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+)
+
+// Values accepted for Check.Ensure.
+const (
+	EnsurePresent = "present"
+	EnsureAbsent  = "absent"
+)
+
+// CompiledRule is a Rule whose checks have been compiled into matchers that
+// can be executed against Go source.
+type CompiledRule struct {
+	Rule   *Rule
+	checks []compiledCheck
+}
+
+type compiledCheck struct {
+	Check
+	pattern *regexp.Regexp
+	target  *regexp.Regexp
+	after   *regexp.Regexp
+}
+
+// span is a half-open byte range [start, end) of a source file.
+type span struct {
+	start, end int
+}
+
+// CompileRule compiles every check of a rule. Patterns are Go regular
+// expressions evaluated in multi-line mode, so ^ and $ match at line
+// boundaries; a check that needs '.' to cross lines can opt in with (?s).
+func CompileRule(rule *Rule) (*CompiledRule, error) {
+	compiled := &CompiledRule{Rule: rule}
+
+	for _, check := range rule.Checks {
+		if check.Ensure != EnsurePresent && check.Ensure != EnsureAbsent {
+			return nil, fmt.Errorf("rule %s: check %s: unknown ensure value %q", rule.ID, check.Name, check.Ensure)
+		}
+
+		cc := compiledCheck{Check: check}
+
+		var err error
+		if cc.pattern, err = compilePattern(check.Pattern); err != nil {
+			return nil, fmt.Errorf("rule %s: check %s: invalid pattern: %w", rule.ID, check.Name, err)
+		}
+		if check.Target != "" {
+			if cc.target, err = compilePattern(check.Target); err != nil {
+				return nil, fmt.Errorf("rule %s: check %s: invalid target: %w", rule.ID, check.Name, err)
+			}
+		}
+		if check.After != "" {
+			if cc.after, err = compilePattern(check.After); err != nil {
+				return nil, fmt.Errorf("rule %s: check %s: invalid after: %w", rule.ID, check.Name, err)
+			}
+		}
+
+		compiled.checks = append(compiled.checks, cc)
+	}
+
+	return compiled, nil
+}
+
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	// Compile the expression as written first so errors quote the author's
+	// pattern rather than the flagged one.
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, err
+	}
+	return regexp.Compile("(?m)" + expr)
+}
+
+// Execute runs the compiled checks against a source file.
+//
+// A check is scoped structurally: every match of Target selects the
+// top-level declaration it falls in (or the whole file when it falls outside
+// any declaration), and the pattern is evaluated only inside those regions.
+// Without a Target the whole file is the region. After further narrows each
+// region to the text that follows every match of After, up to the end of the
+// enclosing declaration.
+func (cr *CompiledRule) Execute(filename string, src []byte) []ast.Issue {
+	sf := newSourceFile(filename, src)
+
+	var issues []ast.Issue
+	for _, check := range cr.checks {
+		for _, offset := range sf.run(check) {
+			issues = append(issues, ast.Issue{
+				RuleID:      cr.Rule.ID,
+				Description: checkDescription(check),
+				Severity:    cr.Rule.Severity,
+				Position:    sf.position(offset),
+			})
+		}
+	}
+
+	return issues
+}
+
+func checkDescription(check compiledCheck) string {
+	if check.Ensure == EnsurePresent {
+		return fmt.Sprintf("Rule check '%s' failed: required pattern not found", check.Name)
+	}
+	return fmt.Sprintf("Rule check '%s' failed: forbidden pattern found", check.Name)
+}
+
+type sourceFile struct {
+	src   []byte
+	file  *token.File
+	decls []span
+	// anchor is where file-wide findings are reported: the package clause
+	// when the file parses, otherwise the start of the file.
+	anchor int
+}
+
+func newSourceFile(filename string, src []byte) *sourceFile {
+	sf := &sourceFile{src: src}
+
+	fset := token.NewFileSet()
+	// A partial AST is still useful for scoping, so parse errors are ignored.
+	f, _ := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if f != nil && f.Package.IsValid() {
+		sf.file = fset.File(f.Package)
+		sf.anchor = sf.file.Offset(f.Package)
+		for _, decl := range f.Decls {
+			sf.decls = append(sf.decls, sf.nodeSpan(decl))
+		}
+	} else {
+		sf.file = fset.AddFile(filename, -1, len(src))
+		sf.file.SetLinesForContent(src)
+	}
+
+	return sf
+}
+
+func (sf *sourceFile) nodeSpan(node goast.Node) span {
+	start := sf.file.Offset(node.Pos())
+	end := len(sf.src)
+	if node.End().IsValid() && int(node.End()) <= sf.file.Base()+sf.file.Size() {
+		end = sf.file.Offset(node.End())
+	}
+	return span{start, end}
+}
+
+func (sf *sourceFile) position(offset int) token.Position {
+	return sf.file.PositionFor(sf.file.Pos(offset), false)
+}
+
+// enclosing returns the top-level declaration containing offset, or the
+// whole file if there is none.
+func (sf *sourceFile) enclosing(offset int) span {
+	for _, decl := range sf.decls {
+		if offset >= decl.start && offset < decl.end {
+			return decl
+		}
+	}
+	return span{0, len(sf.src)}
+}
+
+// run evaluates a single check and returns the offsets of its findings.
+func (sf *sourceFile) run(check compiledCheck) []int {
+	type region struct {
+		span
+		anchor int
+	}
+
+	var regions []region
+	if check.target != nil {
+		seen := make(map[span]bool)
+		for _, m := range check.target.FindAllIndex(sf.src, -1) {
+			r := sf.enclosing(m[0])
+			if !seen[r] {
+				seen[r] = true
+				regions = append(regions, region{r, m[0]})
+			}
+		}
+	} else {
+		regions = append(regions, region{span{0, len(sf.src)}, sf.anchor})
+	}
+
+	findings := make(map[int]bool)
+	for _, r := range regions {
+		if check.after == nil {
+			sf.evaluate(check, r.span, r.anchor, findings)
+			continue
+		}
+
+		for _, m := range check.after.FindAllIndex(sf.src[r.start:r.end], -1) {
+			start, end := r.start+m[0], r.start+m[1]
+			limit := r.end
+			if check.target == nil {
+				limit = sf.enclosing(start).end
+			}
+			sf.evaluate(check, span{end, limit}, start, findings)
+		}
+	}
+
+	offsets := make([]int, 0, len(findings))
+	for offset := range findings {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	return offsets
+}
+
+// evaluate applies a check's pattern to one region, recording a finding at
+// anchor when a required pattern is missing, or at each forbidden match.
+func (sf *sourceFile) evaluate(check compiledCheck, scope span, anchor int, findings map[int]bool) {
+	if scope.end < scope.start {
+		return
+	}
+	text := sf.src[scope.start:scope.end]
+
+	switch check.Ensure {
+	case EnsurePresent:
+		if !check.pattern.Match(text) {
+			findings[anchor] = true
+		}
+	case EnsureAbsent:
+		for _, m := range check.pattern.FindAllIndex(text, -1) {
+			findings[scope.start+m[0]] = true
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"testing"
+)
+
+func TestCompiledRuleExecute(t *testing.T) {
+	tests := []struct {
+		name          string
+		check         Check
+		code          string
+		expectedLines []int
+	}{
+		{
+			name: "Forbidden pattern found",
+			check: Check{
+				Name:    "no_weak_crypto",
+				Pattern: "crypto/md5|crypto/sha1",
+				Ensure:  "absent",
+			},
+			code: `package test
+
+import "crypto/md5"
+`,
+			expectedLines: []int{3},
+		},
+		{
+			name: "Required pattern missing in target",
+			check: Check{
+				Name:    "context_first_param",
+				Pattern: "func.*\\(ctx context\\.Context",
+				Ensure:  "present",
+				Target:  "^func .*\\(.*\\)",
+			},
+			code: `package test
+
+import "context"
+
+func Good(ctx context.Context) {}
+
+func Bad(id string) {}
+`,
+			expectedLines: []int{7},
+		},
+		{
+			name: "Required pattern after anchor",
+			check: Check{
+				Name:    "no_ignored_errors",
+				Pattern: "if err != nil",
+				Ensure:  "present",
+				After:   "func.*\\(.*\\).*error",
+			},
+			code: `package test
+
+func checked() error {
+	err := run()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func unchecked() error {
+	err := run()
+	return err
+}
+`,
+			expectedLines: []int{11},
+		},
+		{
+			name: "Forbidden pattern only after anchor",
+			check: Check{
+				Name:    "unsynchronized_map_access",
+				Pattern: "m\\[.+?\\] = ",
+				Ensure:  "absent",
+				After:   "go func",
+			},
+			code: `package test
+
+func access() {
+	m := make(map[string]string)
+	m["before"] = "ok"
+	go func() {
+		m["after"] = "racy"
+	}()
+}
+`,
+			expectedLines: []int{7},
+		},
+		{
+			name: "Compliant code",
+			check: Check{
+				Name:    "no_underscore_errors",
+				Pattern: "_ = .*err",
+				Ensure:  "absent",
+			},
+			code: `package test
+
+func run() error {
+	return nil
+}
+`,
+			expectedLines: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{
+				ID:       "test_rule",
+				Severity: "warning",
+				Checks:   []Check{tt.check},
+			}
+
+			compiled, err := CompileRule(rule)
+			if err != nil {
+				t.Fatalf("Failed to compile rule: %v", err)
+			}
+
+			issues := compiled.Execute("test.go", []byte(tt.code))
+			if len(issues) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d issues, got %d: %+v", len(tt.expectedLines), len(issues), issues)
+			}
+
+			for i, issue := range issues {
+				if issue.Position.Line != tt.expectedLines[i] {
+					t.Errorf("Issue %d: expected line %d, got %d", i+1, tt.expectedLines[i], issue.Position.Line)
+				}
+				if issue.RuleID != rule.ID || issue.Severity != rule.Severity {
+					t.Errorf("Issue %d: unexpected rule metadata %s/%s", i+1, issue.RuleID, issue.Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileRuleRejectsInvalidChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		check Check
+	}{
+		{
+			name:  "Invalid regex",
+			check: Check{Name: "bad", Pattern: "func(", Ensure: "absent"},
+		},
+		{
+			name:  "Unknown ensure",
+			check: Check{Name: "bad", Pattern: "func", Ensure: "sometimes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileRule(&Rule{ID: "test_rule", Checks: []Check{tt.check}})
+			if err == nil {
+				t.Error("Expected compile error, got nil")
+			}
+		})
+	}
+}
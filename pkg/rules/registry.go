@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RuleRegistry is an in-memory index of the rules in a rules directory. Rules
+// are keyed by their declared id and can also be found through their aliases,
+// their path relative to the rules directory (without extension), their file
+// name, or their category.
+type RuleRegistry struct {
+	rules      map[string]*Rule
+	paths      map[string]string
+	aliases    map[string]string
+	categories map[string][]string
+}
+
+// DuplicateRuleError reports two rule files declaring the same id or alias.
+type DuplicateRuleError struct {
+	Name   string
+	First  string
+	Second string
+}
+
+func (e *DuplicateRuleError) Error() string {
+	return fmt.Sprintf("duplicate rule id %q declared in %s and %s", e.Name, e.First, e.Second)
+}
+
+// NewRuleRegistry walks rulesDir and indexes every rule file it contains. All
+// load errors and duplicate ids are reported together.
+func NewRuleRegistry(rulesDir string) (*RuleRegistry, error) {
+	reg := &RuleRegistry{
+		rules:      make(map[string]*Rule),
+		paths:      make(map[string]string),
+		aliases:    make(map[string]string),
+		categories: make(map[string][]string),
+	}
+
+	var errs []error
+	var implicit [][2]string
+
+	err := filepath.WalkDir(rulesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".yaml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load rule: %w", err))
+			return nil
+		}
+
+		rule, err := ParseRule(path, data)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+
+		if prev, ok := reg.paths[rule.ID]; ok {
+			errs = append(errs, &DuplicateRuleError{Name: rule.ID, First: prev, Second: path})
+			return nil
+		}
+		reg.rules[rule.ID] = rule
+		reg.paths[rule.ID] = path
+
+		if rule.Category != "" {
+			reg.categories[rule.Category] = append(reg.categories[rule.Category], rule.ID)
+		}
+
+		rel, err := filepath.Rel(rulesDir, path)
+		if err != nil {
+			rel = d.Name()
+		}
+		rel = strings.TrimSuffix(filepath.ToSlash(rel), ".yaml")
+		implicit = append(implicit, [2]string{rel, rule.ID}, [2]string{filepath.Base(rel), rule.ID})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	// Declared aliases must be unambiguous.
+	for _, id := range reg.IDs() {
+		for _, alias := range reg.rules[id].Aliases {
+			if other, ok := reg.resolveID(alias); ok && other != id {
+				errs = append(errs, &DuplicateRuleError{Name: alias, First: reg.paths[other], Second: reg.paths[id]})
+				continue
+			}
+			reg.aliases[alias] = id
+		}
+	}
+
+	// Path-derived aliases only fill gaps left by ids and declared aliases.
+	for _, pair := range implicit {
+		if _, ok := reg.resolveID(pair[0]); !ok {
+			reg.aliases[pair[0]] = pair[1]
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return reg, nil
+}
+
+func (reg *RuleRegistry) resolveID(name string) (string, bool) {
+	if _, ok := reg.rules[name]; ok {
+		return name, true
+	}
+	id, ok := reg.aliases[name]
+	return id, ok
+}
+
+// Lookup returns the rule with the given id or alias.
+func (reg *RuleRegistry) Lookup(name string) (*Rule, bool) {
+	id, ok := reg.resolveID(name)
+	if !ok {
+		return nil, false
+	}
+	return reg.rules[id], true
+}
+
+// Resolve expands a rule id, alias or category into the rules it names.
+func (reg *RuleRegistry) Resolve(name string) []*Rule {
+	if rule, ok := reg.Lookup(name); ok {
+		return []*Rule{rule}
+	}
+
+	var rules []*Rule
+	for _, id := range reg.categories[name] {
+		rules = append(rules, reg.rules[id])
+	}
+	return rules
+}
+
+// Path returns the file a rule was loaded from.
+func (reg *RuleRegistry) Path(name string) (string, bool) {
+	id, ok := reg.resolveID(name)
+	if !ok {
+		return "", false
+	}
+	return reg.paths[id], true
+}
+
+// IDs returns the declared ids of all rules in sorted order.
+func (reg *RuleRegistry) IDs() []string {
+	ids := make([]string, 0, len(reg.rules))
+	for id := range reg.rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Rules returns all rules sorted by id.
+func (reg *RuleRegistry) Rules() []*Rule {
+	var rules []*Rule
+	for _, id := range reg.IDs() {
+		rules = append(rules, reg.rules[id])
+	}
+	return rules
+}
+
+// Categories returns the category names in sorted order.
+func (reg *RuleRegistry) Categories() []string {
+	categories := make([]string, 0, len(reg.categories))
+	for category := range reg.categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRule(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuleRegistryLookup(t *testing.T) {
+	registry, err := NewRuleRegistry("../../server/mcpserver/rules")
+	if err != nil {
+		t.Fatalf("Failed to build registry: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		lookup     string
+		expectedID string
+	}{
+		{name: "Declared id", lookup: "concurrent_map_access", expectedID: "concurrent_map_access"},
+		{name: "Declared alias", lookup: "synchronization", expectedID: "concurrent_map_access"},
+		{name: "Relative path", lookup: "concurrency/synchronization", expectedID: "concurrent_map_access"},
+		{name: "File name", lookup: "coding_standards", expectedID: "org_coding_standards"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := registry.Lookup(tt.lookup)
+			if !ok {
+				t.Fatalf("Rule %q not found", tt.lookup)
+			}
+			if rule.ID != tt.expectedID {
+				t.Errorf("Expected rule %s, got %s", tt.expectedID, rule.ID)
+			}
+		})
+	}
+
+	if rules := registry.Resolve("security"); len(rules) != 1 || rules[0].ID != "secure_coding" {
+		t.Errorf("Expected category security to resolve to secure_coding, got %v", rules)
+	}
+
+	if _, ok := registry.Lookup("unknown_rule"); ok {
+		t.Error("Expected unknown rule lookup to fail")
+	}
+}
+
+func TestRuleRegistryDuplicateIDs(t *testing.T) {
+	dir := t.TempDir()
+	rule := "id: duplicated\ndescription: Duplicated rule\nseverity: warning\n"
+	writeRule(t, dir, "a.yaml", rule)
+	writeRule(t, dir, "nested/b.yaml", rule)
+
+	_, err := NewRuleRegistry(dir)
+
+	var dupErr *DuplicateRuleError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("Expected DuplicateRuleError, got: %v", err)
+	}
+	if dupErr.Name != "duplicated" {
+		t.Errorf("Expected duplicate id 'duplicated', got %q", dupErr.Name)
+	}
+}
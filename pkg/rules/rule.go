@@ -0,0 +1,31 @@
+// Package rules loads governance rules from YAML files, validates them
+// against the rule schema, indexes them in a registry and compiles their
+// pattern checks.
+package rules
+
+// Rule is a governance rule as declared in a YAML rule file.
+type Rule struct {
+	ID          string   `json:"id" yaml:"id"`
+	Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Description string   `json:"description" yaml:"description"`
+	Rationale   string   `json:"rationale" yaml:"rationale"`
+	Category    string   `json:"category" yaml:"category"`
+	Severity    string   `json:"severity" yaml:"severity"`
+	Checks      []Check  `json:"checks" yaml:"checks"`
+	// Template names the code template that shows the rule followed, such
+	// as go/service.
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	// Allow lists code the rule's analysis accepts, such as the calls
+	// whose errors discarded_errors lets callers drop or the functions
+	// no_panic lets panic.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+}
+
+// Check is a pattern check of a rule, run by CompiledRule.Execute.
+type Check struct {
+	Name    string `json:"name" yaml:"name"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+	Ensure  string `json:"ensure" yaml:"ensure"`
+	Target  string `json:"target,omitempty" yaml:"target,omitempty"`
+	After   string `json:"after,omitempty" yaml:"after,omitempty"`
+}
@@ -0,0 +1,283 @@
+package rules
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values accepted for Rule.Severity.
+var validSeverities = []string{"error", "warning", "info"}
+
+var (
+	ruleIDPattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+)
+
+var (
+	ruleFields  = []string{"id", "aliases", "description", "rationale", "category", "severity", "checks", "template", "allow"}
+	checkFields = []string{"name", "pattern", "ensure", "target", "after"}
+)
+
+// RuleError describes a problem found while loading a rule file.
+type RuleError struct {
+	Path   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *RuleError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Msg)
+}
+
+// RuleErrors is the list of problems found in a single rule file.
+type RuleErrors []*RuleError
+
+func (errs RuleErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ParseRule decodes a YAML rule definition and validates it against the rule
+// schema. Problems are returned as RuleErrors carrying the line and column of
+// the offending node.
+func ParseRule(path string, data []byte) (*Rule, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, RuleErrors{syntaxError(path, err)}
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, RuleErrors{{Path: path, Msg: "empty rule file"}}
+	}
+
+	root := doc.Content[0]
+	v := &ruleValidator{path: path}
+	if root.Kind != yaml.MappingNode {
+		v.errorf(root, "rule must be a mapping, found %s", kindName(root))
+		return nil, v.errs
+	}
+
+	var rule Rule
+	if err := root.Decode(&rule); err != nil {
+		return nil, RuleErrors{syntaxError(path, err)}
+	}
+
+	v.validateRule(root, &rule)
+	if len(v.errs) > 0 {
+		return nil, v.errs
+	}
+
+	return &rule, nil
+}
+
+func syntaxError(path string, err error) *RuleError {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	line := 0
+	if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		msg = strings.TrimSpace(strings.Replace(msg, m[0]+":", "", 1))
+	}
+	return &RuleError{Path: path, Line: line, Column: 1, Msg: msg}
+}
+
+type ruleValidator struct {
+	path string
+	errs RuleErrors
+}
+
+func (v *ruleValidator) errorf(node *yaml.Node, format string, args ...interface{}) {
+	v.errs = append(v.errs, &RuleError{
+		Path:   v.path,
+		Line:   node.Line,
+		Column: node.Column,
+		Msg:    fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *ruleValidator) validateRule(root *yaml.Node, rule *Rule) {
+	v.checkFields(root, ruleFields, "rule")
+
+	if node := v.required(root, "id"); node != nil && !ruleIDPattern.MatchString(rule.ID) {
+		v.errorf(node, "invalid id %q: must be lower snake_case", rule.ID)
+	}
+
+	if aliases := mappingValue(root, "aliases"); aliases != nil {
+		for i, alias := range rule.Aliases {
+			if !ruleIDPattern.MatchString(alias) || alias == rule.ID {
+				v.errorf(aliases.Content[i], "invalid alias %q: must be lower snake_case and differ from the id", alias)
+			}
+		}
+	}
+
+	v.required(root, "description")
+
+	if node := v.required(root, "severity"); node != nil && !contains(validSeverities, rule.Severity) {
+		v.errorf(node, "invalid severity %q: must be one of %s", rule.Severity, strings.Join(validSeverities, ", "))
+	}
+
+	checks := mappingValue(root, "checks")
+	if checks == nil {
+		return
+	}
+	if checks.Kind != yaml.SequenceNode {
+		v.errorf(checks, "checks must be a list, found %s", kindName(checks))
+		return
+	}
+
+	names := make(map[string]bool)
+	for i, node := range checks.Content {
+		if node.Kind != yaml.MappingNode {
+			v.errorf(node, "check must be a mapping, found %s", kindName(node))
+			continue
+		}
+		check := rule.Checks[i]
+
+		v.checkFields(node, checkFields, "check")
+
+		if name := v.required(node, "name"); name != nil {
+			if names[check.Name] {
+				v.errorf(name, "duplicate check name %q", check.Name)
+			}
+			names[check.Name] = true
+		}
+
+		if ensure := v.required(node, "ensure"); ensure != nil &&
+			check.Ensure != EnsurePresent && check.Ensure != EnsureAbsent {
+			v.errorf(ensure, "invalid ensure %q: must be %s or %s", check.Ensure, EnsurePresent, EnsureAbsent)
+		}
+
+		v.regex(node, "pattern", true)
+		v.regex(node, "target", false)
+		v.regex(node, "after", false)
+	}
+}
+
+// checkFields reports keys that are not part of the schema.
+func (v *ruleValidator) checkFields(node *yaml.Node, allowed []string, kind string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !contains(allowed, key.Value) {
+			v.errorf(key, "unknown %s field %q", kind, key.Value)
+		}
+	}
+}
+
+// required returns the value node for key, reporting an error if it is
+// missing or empty.
+func (v *ruleValidator) required(node *yaml.Node, key string) *yaml.Node {
+	value := mappingValue(node, key)
+	if value == nil {
+		v.errorf(node, "missing required field %q", key)
+		return nil
+	}
+	if value.Kind != yaml.ScalarNode || value.Value == "" {
+		v.errorf(value, "field %q must be a non-empty string", key)
+		return nil
+	}
+	return value
+}
+
+func (v *ruleValidator) regex(node *yaml.Node, key string, required bool) {
+	var value *yaml.Node
+	if required {
+		value = v.required(node, key)
+	} else {
+		value = mappingValue(node, key)
+	}
+	if value == nil {
+		return
+	}
+
+	if _, err := compilePattern(value.Value); err != nil {
+		v.errorf(value, "invalid %s regex: %v", key, err)
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func kindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "document"
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LintRules loads every rule file under dir and returns all problems found.
+// An error is returned only when the directory itself cannot be walked.
+func LintRules(dir string) ([]*RuleError, error) {
+	var problems []*RuleError
+	ids := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".yaml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, &RuleError{Path: path, Msg: err.Error()})
+			return nil
+		}
+
+		rule, err := ParseRule(path, data)
+		if err != nil {
+			problems = append(problems, err.(RuleErrors)...)
+			return nil
+		}
+
+		if prev, ok := ids[rule.ID]; ok {
+			problems = append(problems, &RuleError{
+				Path: path,
+				Msg:  fmt.Sprintf("duplicate rule id %q (also declared in %s)", rule.ID, prev),
+			})
+		}
+		ids[rule.ID] = path
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lint rules: %w", err)
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		expectedLines []int
+	}{
+		{
+			name: "Valid rule",
+			yaml: `id: error_handling
+description: Ensures proper error handling
+severity: warning
+checks:
+  - name: no_underscore_errors
+    pattern: "_ = .*err"
+    ensure: absent
+`,
+			expectedLines: nil,
+		},
+		{
+			name: "Missing id and invalid severity",
+			yaml: `description: Missing id
+severity: fatal
+`,
+			expectedLines: []int{1, 2},
+		},
+		{
+			name: "Invalid check",
+			yaml: `id: broken
+description: Broken check
+severity: error
+checks:
+  - name: bad_regex
+    pattern: "func("
+    ensure: sometimes
+`,
+			expectedLines: []int{7, 6},
+		},
+		{
+			name: "Unknown field",
+			yaml: `id: extra
+description: Extra field
+severity: info
+owner: platform
+`,
+			expectedLines: []int{4},
+		},
+		{
+			name:          "Syntax error",
+			yaml:          "id: a\n  b: : c\n",
+			expectedLines: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule("rule.yaml", []byte(tt.yaml))
+			if len(tt.expectedLines) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				if rule.ID == "" || len(rule.Checks) == 0 {
+					t.Errorf("Rule not decoded: %+v", rule)
+				}
+				return
+			}
+
+			var ruleErrs RuleErrors
+			if !errors.As(err, &ruleErrs) {
+				t.Fatalf("Expected RuleErrors, got: %v", err)
+			}
+			if len(ruleErrs) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d errors, got %d: %v", len(tt.expectedLines), len(ruleErrs), err)
+			}
+			for i, ruleErr := range ruleErrs {
+				if ruleErr.Line != tt.expectedLines[i] {
+					t.Errorf("Error %d: expected line %d, got %d (%v)", i+1, tt.expectedLines[i], ruleErr.Line, ruleErr)
+				}
+			}
+		})
+	}
+}
+
+func TestShippedRulesAreValid(t *testing.T) {
+	problems, err := LintRules("../../server/mcpserver/rules")
+	if err != nil {
+		t.Fatalf("Failed to lint rules: %v", err)
+	}
+
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
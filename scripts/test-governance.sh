@@ -7,7 +7,7 @@ PROJECT_ROOT="$(cd "${SCRIPT_DIR}/.." && pwd)"
 
 # Ensure mcplsp is built
 cd "${PROJECT_ROOT}"
-go build -o "${PROJECT_ROOT}/bin/mcplsp" "${PROJECT_ROOT}/cmd/mcplsp/main.go"
+go build -o "${PROJECT_ROOT}/bin/mcplsp" "${PROJECT_ROOT}/cmd/mcplsp"
 
 # Test directories and their corresponding rules
 declare -A TEST_DIRS=(
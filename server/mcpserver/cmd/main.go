@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/yourorg/go-mcp-lsp/server/mcpserver"
@@ -13,19 +15,64 @@ import (
 
 func main() {
 	var (
-		address     = flag.String("address", "localhost:9000", "Address to listen on")
-		rulesDir    = flag.String("rules", "./rules", "Path to rules directory")
+		configPath   = flag.String("config", "", "Path to a JSON config file; explicitly set flags override it")
+		address      = flag.String("address", "localhost:9000", "Address to listen on")
+		rulesDir     = flag.String("rules", "./rules", "Path to rules directory")
 		templatesDir = flag.String("templates", "./templates", "Path to templates directory")
+		transport    = flag.String("transport", "tcp", "Transport: tcp (legacy net/rpc JSON-RPC 1.0), stdio or http (Model Context Protocol)")
+		httpPath     = flag.String("http-path", "/mcp", "MCP endpoint path for the http transport")
+		stream       = flag.Bool("stream", false, "Answer HTTP requests with resumable SSE streams when the client accepts them")
+		timeout      = flag.String("session-timeout", "30m", "Idle timeout for HTTP sessions")
+		origins      = flag.String("allowed-origins", "", "Comma-separated browser origins the http transport accepts besides loopback")
 	)
 
 	flag.Parse()
 
-	absRulesDir, err := filepath.Abs(*rulesDir)
+	config := &mcpserver.Config{
+		Address:      *address,
+		RulesDir:     *rulesDir,
+		TemplatesDir: *templatesDir,
+		Transport:    *transport,
+		HTTP: mcpserver.HTTPConfig{
+			Path:            *httpPath,
+			StreamResponses: *stream,
+			SessionTimeout:  *timeout,
+			AllowedOrigins:  splitList(*origins),
+		},
+	}
+	if *configPath != "" {
+		var err error
+		if config, err = mcpserver.LoadConfig(*configPath); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "address":
+				config.Address = *address
+			case "rules":
+				config.RulesDir = *rulesDir
+			case "templates":
+				config.TemplatesDir = *templatesDir
+			case "transport":
+				config.Transport = *transport
+			case "http-path":
+				config.HTTP.Path = *httpPath
+			case "stream":
+				config.HTTP.StreamResponses = *stream
+			case "session-timeout":
+				config.HTTP.SessionTimeout = *timeout
+			case "allowed-origins":
+				config.HTTP.AllowedOrigins = splitList(*origins)
+			}
+		})
+	}
+
+	absRulesDir, err := filepath.Abs(config.RulesDir)
 	if err != nil {
 		log.Fatalf("Failed to resolve rules directory: %v", err)
 	}
 
-	absTemplatesDir, err := filepath.Abs(*templatesDir)
+	absTemplatesDir, err := filepath.Abs(config.TemplatesDir)
 	if err != nil {
 		log.Fatalf("Failed to resolve templates directory: %v", err)
 	}
@@ -35,6 +82,22 @@ func main() {
 		log.Fatalf("Failed to create MCP server: %v", err)
 	}
 
+	switch config.Transport {
+	case "stdio":
+		// stdout carries the protocol; logs go to stderr
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		log.Println("Starting MCP server on stdio")
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			log.Fatalf("Server error: %v", err)
+		}
+		return
+	case "", "tcp", "http":
+	default:
+		log.Fatalf("Unknown transport: %s", config.Transport)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -47,8 +110,32 @@ func main() {
 		os.Exit(0)
 	}()
 
-	log.Printf("Starting MCP server on %s", *address)
-	if err := server.Start(*address); err != nil {
+	if config.Transport == "http" {
+		opts, err := config.HTTP.Options()
+		if err != nil {
+			log.Fatalf("Invalid HTTP config: %v", err)
+		}
+
+		log.Printf("Starting MCP server on %s over HTTP", config.Address)
+		if err := server.StartHTTP(config.Address, opts); err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Starting MCP server on %s", config.Address)
+	if err := server.Start(config.Address); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
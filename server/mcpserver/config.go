@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/yourorg/go-mcp-lsp/server/mcpserver/endpoints"
 )
@@ -14,6 +15,32 @@ type Config struct {
 	RulesDir    string `json:"rulesDir"`
 	TemplatesDir string `json:"templatesDir"`
 	LogFile     string `json:"logFile,omitempty"`
+	// Transport is tcp (legacy net/rpc, the default), stdio or http.
+	Transport string `json:"transport,omitempty"`
+	// HTTP configures the http transport.
+	HTTP HTTPConfig `json:"http,omitempty"`
+}
+
+type HTTPConfig struct {
+	Path            string `json:"path,omitempty"`
+	StreamResponses bool   `json:"streamResponses,omitempty"`
+	// SessionTimeout is a time.ParseDuration string such as "30m".
+	SessionTimeout string `json:"sessionTimeout,omitempty"`
+	// AllowedOrigins are the browser origins accepted besides loopback.
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+}
+
+// Options converts the configuration into HTTPOptions.
+func (c HTTPConfig) Options() (HTTPOptions, error) {
+	opts := HTTPOptions{Path: c.Path, StreamResponses: c.StreamResponses, AllowedOrigins: c.AllowedOrigins}
+	if c.SessionTimeout != "" {
+		timeout, err := time.ParseDuration(c.SessionTimeout)
+		if err != nil {
+			return opts, fmt.Errorf("invalid session timeout: %w", err)
+		}
+		opts.SessionTimeout = timeout
+	}
+	return opts, nil
 }
 
 func LoadConfig(path string) (*Config, error) {
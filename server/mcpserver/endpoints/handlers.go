@@ -1,35 +1,28 @@
 package endpoints
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"text/template"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+	"github.com/yourorg/go-mcp-lsp/pkg/analyzer/ast"
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
 )
 
 type ResourceManager struct {
 	RulesDir     string
 	TemplatesDir string
-}
-
-type Rule struct {
-	ID          string   `json:"id"`
-	Description string   `json:"description"`
-	Rationale   string   `json:"rationale"`
-	Category    string   `json:"category"`
-	Severity    string   `json:"severity"`
-	Checks      []Check  `json:"checks"`
-}
 
-type Check struct {
-	Name    string `json:"name"`
-	Pattern string `json:"pattern"`
-	Ensure  string `json:"ensure"`
-	Target  string `json:"target,omitempty"`
-	After   string `json:"after,omitempty"`
+	mu       sync.Mutex
+	registry *rules.RuleRegistry
+	// engine holds the rules' compiled checks and the importer shared by
+	// every analysis; analyses run on forks of it, one at a time
+	engine    *analyzer.AnalyzerEngine
+	analyzeMu sync.Mutex
 }
 
 func NewResourceManager(rulesDir, templatesDir string) *ResourceManager {
@@ -39,46 +32,112 @@ func NewResourceManager(rulesDir, templatesDir string) *ResourceManager {
 	}
 }
 
-func (rm *ResourceManager) LoadRule(id string) (*Rule, error) {
-	path := filepath.Join(rm.RulesDir, id+".yaml")
-	data, err := os.ReadFile(path)
+// Registry returns the rule registry for RulesDir, building it on first use.
+func (rm *ResourceManager) Registry() (*rules.RuleRegistry, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if err := rm.load(); err != nil {
+		return nil, err
+	}
+	return rm.registry, nil
+}
+
+// ResolveRuleIDs expands rule ids, aliases and categories into rule ids.
+func (rm *ResourceManager) ResolveRuleIDs(names []string) ([]string, error) {
+	engine, err := rm.loadEngine()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load rule: %w", err)
+		return nil, err
 	}
+	return engine.ResolveRuleIDs(names)
+}
 
-	var rule Rule
-	if err := json.Unmarshal(data, &rule); err != nil {
-		return nil, fmt.Errorf("failed to parse rule: %w", err)
+// Analyze type-checks code and runs the rules with the given ids over it.
+// The rules' checks are compiled once, on first use and after Reload.
+// Analyses run one at a time, since they share the importer that
+// type-checks the packages code imports.
+func (rm *ResourceManager) Analyze(filename string, code []byte, ruleIDs []string) (*analyzer.AnalysisResult, error) {
+	engine, err := rm.loadEngine()
+	if err != nil {
+		return nil, err
 	}
 
-	return &rule, nil
+	rm.analyzeMu.Lock()
+	defer rm.analyzeMu.Unlock()
+	return engine.Fork().Analyze(filename, code, ruleIDs)
 }
 
-func (rm *ResourceManager) ListRules() ([]string, error) {
-	var rules []string
-
-	err := filepath.WalkDir(rm.RulesDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+func (rm *ResourceManager) loadEngine() (*analyzer.AnalyzerEngine, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
 
-		if !d.IsDir() && strings.HasSuffix(d.Name(), ".yaml") {
-			ruleName := strings.TrimSuffix(d.Name(), ".yaml")
-			rules = append(rules, ruleName)
-		}
+	if err := rm.load(); err != nil {
+		return nil, err
+	}
+	return rm.engine, nil
+}
 
+// load builds the registry and engine if they have not been built yet.
+func (rm *ResourceManager) load() error {
+	if rm.registry != nil {
 		return nil
-	})
+	}
+
+	registry, err := rules.NewRuleRegistry(rm.RulesDir)
+	if err != nil {
+		return err
+	}
+	engine, err := analyzer.NewAnalyzerEngineWithConfig(ast.AnalyzerConfig{TypeCheck: true}, registry)
+	if err != nil {
+		return fmt.Errorf("invalid rules: %w", err)
+	}
 
+	rm.registry, rm.engine = registry, engine
+	return nil
+}
+
+// Reload rebuilds the rule registry and engine from RulesDir.
+func (rm *ResourceManager) Reload() (*rules.RuleRegistry, error) {
+	rm.mu.Lock()
+	rm.registry, rm.engine = nil, nil
+	rm.mu.Unlock()
+
+	return rm.Registry()
+}
+
+func (rm *ResourceManager) LoadRule(id string) (*rules.Rule, error) {
+	registry, err := rm.Registry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rule: %w", err)
+	}
+
+	rule, ok := registry.Lookup(id)
+	if !ok {
+		return nil, fmt.Errorf("failed to load rule: unknown rule %q", id)
+	}
+
+	return rule, nil
+}
+
+// ListRules returns the declared ids of all rules in RulesDir.
+func (rm *ResourceManager) ListRules() ([]string, error) {
+	registry, err := rm.Registry()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list rules: %w", err)
 	}
 
-	return rules, nil
+	return registry.IDs(), nil
 }
 
+// RenderTemplate executes the template TemplatesDir/templateName.tmpl with
+// data. Names that are absolute or leave TemplatesDir are rejected, since
+// they come from clients.
 func (rm *ResourceManager) RenderTemplate(templateName string, data interface{}) (string, error) {
-	path := filepath.Join(rm.TemplatesDir, templateName+".tmpl")
+	clean := filepath.Clean(filepath.FromSlash(templateName))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("failed to load template: invalid name %q", templateName)
+	}
+	path := filepath.Join(rm.TemplatesDir, clean+".tmpl")
 	
 	tmplData, err := os.ReadFile(path)
 	if err != nil {
@@ -98,32 +157,18 @@ func (rm *ResourceManager) RenderTemplate(templateName string, data interface{})
 	return buf.String(), nil
 }
 
-func (rm *ResourceManager) ValidateAgainstRule(code string, ruleID string) (bool, []string, error) {
+// ValidateAgainstRule executes the checks declared by a rule against a Go
+// source file and returns the resulting findings.
+func (rm *ResourceManager) ValidateAgainstRule(filename string, code []byte, ruleID string) ([]ast.Issue, error) {
 	rule, err := rm.LoadRule(ruleID)
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
-	
-	var issues []string
-	valid := true
-	
-	// This is a simplified validation that would be replaced with real parsing
-	// and AST analysis in a production implementation
-	for _, check := range rule.Checks {
-		if check.Ensure == "present" {
-			if !strings.Contains(code, check.Pattern) {
-				issues = append(issues, fmt.Sprintf("Rule %s: Required pattern '%s' not found in code", 
-					rule.ID, check.Pattern))
-				valid = false
-			}
-		} else if check.Ensure == "absent" {
-			if strings.Contains(code, check.Pattern) {
-				issues = append(issues, fmt.Sprintf("Rule %s: Forbidden pattern '%s' found in code", 
-					rule.ID, check.Pattern))
-				valid = false
-			}
-		}
+
+	compiled, err := rules.CompileRule(rule)
+	if err != nil {
+		return nil, err
 	}
-	
-	return valid, issues, nil
+
+	return compiled.Execute(filename, code), nil
 }
@@ -0,0 +1,578 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionHeader  = "Mcp-Session-Id"
+	versionHeader  = "Mcp-Protocol-Version"
+	lastEventIDKey = "Last-Event-ID"
+
+	defaultHTTPPath       = "/mcp"
+	defaultSessionTimeout = 30 * time.Minute
+	keepAliveInterval     = 15 * time.Second
+
+	// maxRetainedStreams bounds how many finished streams a session keeps
+	// around for resumption.
+	maxRetainedStreams = 64
+	// maxRetainedEvents bounds how many events a stream keeps for replay.
+	// Older events are dropped, and a client resuming before them receives
+	// the retained ones.
+	maxRetainedEvents = 256
+)
+
+// HTTPOptions configures the Streamable HTTP transport.
+type HTTPOptions struct {
+	// Path is the single MCP endpoint; "/mcp" by default.
+	Path string
+	// StreamResponses answers POSTed requests with an SSE stream, which the
+	// client can resume with Last-Event-ID after a disconnect, instead of a
+	// single JSON body. Clients must accept text/event-stream for it to
+	// apply.
+	StreamResponses bool
+	// SessionTimeout ends sessions idle for longer; 30 minutes by default.
+	SessionTimeout time.Duration
+	// AllowedOrigins lists the origins, such as "https://app.example.com",
+	// that browsers may send requests from besides loopback hosts.
+	AllowedOrigins []string
+}
+
+// HTTPHandler serves MCP over the Streamable HTTP transport: clients POST
+// JSON-RPC messages to a single endpoint, may GET it to open an SSE stream
+// for server-to-client messages, and DELETE it to end their session.
+// Sessions are identified by the Mcp-Session-Id header assigned on
+// initialize.
+type HTTPHandler struct {
+	server *MCPServer
+	opts   HTTPOptions
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+	// expiring is set while a goroutine expires idle sessions
+	expiring bool
+}
+
+type httpSession struct {
+	*Session
+	id string
+
+	mu         sync.Mutex
+	lastSeen   time.Time
+	streams    map[string]*eventStream
+	order      []string
+	standalone *eventStream
+}
+
+// NewHTTPHandler returns the Streamable HTTP handler for the server. It
+// answers on every path; StartHTTP mounts it at opts.Path.
+func (s *MCPServer) NewHTTPHandler(opts HTTPOptions) *HTTPHandler {
+	if opts.Path == "" {
+		opts.Path = defaultHTTPPath
+	}
+	if opts.SessionTimeout <= 0 {
+		opts.SessionTimeout = defaultSessionTimeout
+	}
+	return &HTTPHandler{
+		server:   s,
+		opts:     opts,
+		sessions: make(map[string]*httpSession),
+	}
+}
+
+// StartHTTP serves MCP over HTTP on address until Stop is called.
+func (s *MCPServer) StartHTTP(address string, opts HTTPOptions) error {
+	handler := s.NewHTTPHandler(opts)
+
+	mux := http.NewServeMux()
+	mux.Handle(handler.opts.Path, handler)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	s.httpServer = &http.Server{Handler: mux}
+	log.Printf("MCP server listening on http://%s%s", address, handler.opts.Path)
+
+	if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to serve HTTP: %w", err)
+	}
+	return nil
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.validOrigin(r) {
+		http.Error(w, "forbidden origin", http.StatusForbidden)
+		return
+	}
+	if v := r.Header.Get(versionHeader); v != "" && !supportedVersion(v) {
+		http.Error(w, fmt.Sprintf("unsupported protocol version: %s", v), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxMessageSize {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	requests, err := decodeMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, newError(nil, CodeParseError, "parse error"))
+		return
+	}
+
+	var sess *httpSession
+	if containsMethod(requests, "initialize") {
+		sess = h.newSession()
+		w.Header().Set(sessionHeader, sess.id)
+	} else if sess = h.session(w, r); sess == nil {
+		return
+	}
+
+	if !containsRequest(requests) {
+		// Notifications and responses only: accept them without a body
+		sess.HandleMessage(r.Context(), body)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if !h.opts.StreamResponses || !accepts(r, "text/event-stream") {
+		resp := sess.HandleMessage(r.Context(), body)
+		if resp == nil {
+			// Nothing to answer, as for notifications
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(resp)
+		return
+	}
+
+	// Process the request independently of this connection so that a
+	// client that drops the stream can resume it and still get the result.
+	stream := sess.newStream()
+	go func() {
+		defer stream.close()
+		if resp := sess.HandleMessage(context.Background(), body); resp != nil {
+			stream.append(resp)
+		}
+	}()
+	serveStream(w, r, stream, 0)
+}
+
+func (h *HTTPHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !accepts(r, "text/event-stream") {
+		http.Error(w, "GET requires Accept: text/event-stream", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sess := h.session(w, r)
+	if sess == nil {
+		return
+	}
+
+	if lastID := r.Header.Get(lastEventIDKey); lastID != "" {
+		streamID, seq, ok := parseEventID(lastID)
+		stream := sess.stream(streamID)
+		if !ok || stream == nil {
+			http.Error(w, "unknown event stream", http.StatusNotFound)
+			return
+		}
+		serveStream(w, r, stream, seq)
+		return
+	}
+
+	serveStream(w, r, sess.openStandalone(), 0)
+}
+
+func (h *HTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sess := h.session(w, r)
+	if sess == nil {
+		return
+	}
+
+	h.mu.Lock()
+	delete(h.sessions, sess.id)
+	h.mu.Unlock()
+	sess.closeStreams()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Notify sends a JSON-RPC notification to a session over its standalone
+// GET stream. It fails when the session does not exist; if the client has
+// not opened a stream yet, the message is kept until it does.
+func (h *HTTPHandler) Notify(sessionID, method string, params interface{}) error {
+	h.mu.Lock()
+	sess, ok := h.sessions[sessionID]
+	h.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown session: %s", sessionID)
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	sess.openStandalone().append(encode(Request{JSONRPC: "2.0", Method: method, Params: raw}))
+	return nil
+}
+
+func (h *HTTPHandler) newSession() *httpSession {
+	sess := &httpSession{
+		Session:  h.server.NewSession(),
+		id:       newID(),
+		lastSeen: time.Now(),
+		streams:  make(map[string]*eventStream),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sessions[sess.id] = sess
+	if !h.expiring {
+		h.expiring = true
+		go h.expireSessions()
+	}
+	return sess
+}
+
+// expireSessions ends idle sessions periodically for as long as there are
+// sessions left.
+func (h *HTTPHandler) expireSessions() {
+	ticker := time.NewTicker(h.opts.SessionTimeout / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.mu.Lock()
+		for id, s := range h.sessions {
+			if s.idle() > h.opts.SessionTimeout {
+				delete(h.sessions, id)
+				s.closeStreams()
+			}
+		}
+		if len(h.sessions) == 0 {
+			h.expiring = false
+			h.mu.Unlock()
+			return
+		}
+		h.mu.Unlock()
+	}
+}
+
+// session returns the session named by the request, writing the error
+// response and returning nil if there is none.
+func (h *HTTPHandler) session(w http.ResponseWriter, r *http.Request) *httpSession {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		http.Error(w, "missing "+sessionHeader+" header", http.StatusBadRequest)
+		return nil
+	}
+
+	h.mu.Lock()
+	sess, ok := h.sessions[id]
+	h.mu.Unlock()
+	if !ok || sess.idle() > h.opts.SessionTimeout {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return nil
+	}
+
+	sess.touch()
+	return sess
+}
+
+func (s *httpSession) touch() {
+	s.mu.Lock()
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *httpSession) idle() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastSeen)
+}
+
+func (s *httpSession) newStream() *eventStream {
+	stream := newEventStream()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.streams[stream.id] = stream
+	s.order = append(s.order, stream.id)
+	if len(s.order) > maxRetainedStreams {
+		delete(s.streams, s.order[0])
+		s.order = s.order[1:]
+	}
+	return stream
+}
+
+func (s *httpSession) stream(id string) *eventStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.standalone != nil && s.standalone.id == id {
+		return s.standalone
+	}
+	return s.streams[id]
+}
+
+func (s *httpSession) openStandalone() *eventStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.standalone == nil {
+		s.standalone = newEventStream()
+	}
+	return s.standalone
+}
+
+func (s *httpSession) closeStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stream := range s.streams {
+		stream.close()
+	}
+	if s.standalone != nil {
+		s.standalone.close()
+	}
+}
+
+// eventStream is an append-only sequence of SSE events. The most recent
+// events are kept so that a client can resume the stream after the sequence
+// number of the last event it received.
+type eventStream struct {
+	id string
+
+	mu     sync.Mutex
+	events [][]byte
+	// dropped is the number of events trimmed from the start of events
+	dropped int
+	closed  bool
+	changed chan struct{}
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{id: newID(), changed: make(chan struct{})}
+}
+
+func (st *eventStream) append(msg []byte) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.closed {
+		return
+	}
+	st.events = append(st.events, msg)
+	if n := len(st.events) - maxRetainedEvents; n > 0 {
+		st.events = append([][]byte(nil), st.events[n:]...)
+		st.dropped += n
+	}
+	st.broadcast()
+}
+
+func (st *eventStream) close() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.closed {
+		st.closed = true
+		st.broadcast()
+	}
+}
+
+// broadcast wakes every reader. The caller must hold st.mu.
+func (st *eventStream) broadcast() {
+	close(st.changed)
+	st.changed = make(chan struct{})
+}
+
+// since returns the retained events after sequence number seq with the
+// sequence number preceding the first of them, a channel that is closed on
+// the next change, and whether the stream has ended.
+func (st *eventStream) since(seq int) ([][]byte, int, <-chan struct{}, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	seq = min(max(seq, st.dropped), st.dropped+len(st.events))
+	return st.events[seq-st.dropped:], seq, st.changed, st.closed
+}
+
+// serveStream writes the events of stream after seq as SSE until the stream
+// ends or the client goes away.
+func serveStream(w http.ResponseWriter, r *http.Request, stream *eventStream, seq int) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	rc.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		events, from, changed, closed := stream.since(seq)
+		seq = from
+		for _, data := range events {
+			seq++
+			if _, err := fmt.Fprintf(w, "id: %s/%d\nevent: message\ndata: %s\n\n", stream.id, seq, data); err != nil {
+				return
+			}
+		}
+		if len(events) > 0 {
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+		if closed {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func parseEventID(id string) (string, int, bool) {
+	streamID, seqStr, ok := strings.Cut(id, "/")
+	if !ok {
+		return "", 0, false
+	}
+	seq, err := strconv.Atoi(seqStr)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return streamID, seq, true
+}
+
+// decodeMessages decodes a single message or a batch.
+func decodeMessages(data []byte) ([]Request, error) {
+	if isBatch(data) {
+		var batch []Request
+		err := json.Unmarshal(data, &batch)
+		return batch, err
+	}
+	var req Request
+	err := json.Unmarshal(data, &req)
+	return []Request{req}, err
+}
+
+func containsMethod(requests []Request, method string) bool {
+	for _, req := range requests {
+		if req.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRequest reports whether any message expects a response.
+func containsRequest(requests []Request) bool {
+	for _, req := range requests {
+		if req.Method != "" && !req.IsNotification() {
+			return true
+		}
+	}
+	return false
+}
+
+func accepts(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			t, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if t == mediaType || t == "*/*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validOrigin guards against DNS rebinding: browsers send an Origin header,
+// which must name a loopback host or one of the allowed origins. The Host
+// header is not trusted, since a rebound name sends its own.
+func (h *HTTPHandler) validOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	for _, allowed := range h.opts.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), u.Scheme+"://"+u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+func supportedVersion(version string) bool {
+	for _, v := range supportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(encode(v))
+}
+
+func newID() string {
+	return rand.Text()
+}
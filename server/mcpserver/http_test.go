@@ -0,0 +1,249 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type httpClient struct {
+	t       *testing.T
+	url     string
+	session string
+}
+
+func (c *httpClient) do(ctx context.Context, method, body string, header map[string]string) *http.Response {
+	c.t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.session != "" {
+		req.Header.Set(sessionHeader, c.session)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if host := header["Host"]; host != "" {
+		// The client sends req.Host rather than the header
+		req.Host = host
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp
+}
+
+func (c *httpClient) post(body string) (int, string) {
+	c.t.Helper()
+	resp := c.do(context.Background(), http.MethodPost, body, nil)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+// readEvent returns the id and data of the next SSE event.
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var id, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && data != "":
+			return id, data
+		}
+	}
+}
+
+func TestHTTPTransport(t *testing.T) {
+	handler := newTestServer(t).NewHTTPHandler(HTTPOptions{StreamResponses: true})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	c := &httpClient{t: t, url: ts.URL + "/mcp"}
+
+	// Requests need a session until initialize assigns one
+	if status, _ := c.post(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without a session, got %d", status)
+	}
+
+	resp := c.do(context.Background(), http.MethodPost, initializeRequest, map[string]string{"Accept": "application/json"})
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.session = resp.Header.Get(sessionHeader)
+	if resp.StatusCode != http.StatusOK || c.session == "" {
+		t.Fatalf("initialize: status %d, session %q, body %s", resp.StatusCode, c.session, body)
+	}
+	if !strings.Contains(string(body), `"protocolVersion":"2025-03-26"`) {
+		t.Errorf("initialize: unexpected body %s", body)
+	}
+
+	if status, _ := c.post(`{"jsonrpc":"2.0","method":"notifications/initialized"}`); status != http.StatusAccepted {
+		t.Errorf("expected 202 for a notification, got %d", status)
+	}
+
+	// Streamed response, then resumed from the start of the same stream
+	resp = c.do(context.Background(), http.MethodPost, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, nil)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an SSE response, got %q", ct)
+	}
+	id, data := readEvent(t, bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if !strings.Contains(data, `"name":"validateCode"`) {
+		t.Errorf("unexpected streamed response %s", data)
+	}
+
+	streamID, _, _ := parseEventID(id)
+	resp = c.do(context.Background(), http.MethodGet, "", map[string]string{lastEventIDKey: streamID + "/0"})
+	_, replayed := readEvent(t, bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if replayed != data {
+		t.Errorf("resumed stream replayed %s, want %s", replayed, data)
+	}
+
+	// Server-to-client messages arrive on the standalone GET stream
+	if err := handler.Notify(c.session, "notifications/resources/list_changed", struct{}{}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	resp = c.do(ctx, http.MethodGet, "", nil)
+	_, notification := readEvent(t, bufio.NewReader(resp.Body))
+	cancel()
+	resp.Body.Close()
+	if !strings.Contains(notification, `"method":"notifications/resources/list_changed"`) {
+		t.Errorf("unexpected notification %s", notification)
+	}
+
+	// Ending the session invalidates its id
+	resp = c.do(context.Background(), http.MethodDelete, "", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204 on delete, got %d", resp.StatusCode)
+	}
+	if status, _ := c.post(`{"jsonrpc":"2.0","id":3,"method":"ping"}`); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an ended session, got %d", status)
+	}
+}
+
+func TestHTTPTransportRejects(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t).NewHTTPHandler(HTTPOptions{}))
+	defer ts.Close()
+
+	c := &httpClient{t: t, url: ts.URL + "/mcp"}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		header map[string]string
+		want   int
+	}{
+		{"foreign origin", http.MethodPost, initializeRequest, map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden},
+		{"rebound host", http.MethodPost, initializeRequest, map[string]string{"Origin": "http://evil.example", "Host": "evil.example"}, http.StatusForbidden},
+		{"unsupported version", http.MethodPost, initializeRequest, map[string]string{versionHeader: "1999-01-01"}, http.StatusBadRequest},
+		{"invalid json", http.MethodPost, `{not json`, nil, http.StatusBadRequest},
+		{"unknown session", http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, map[string]string{sessionHeader: "nope"}, http.StatusNotFound},
+		{"put", http.MethodPut, "", nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.do(context.Background(), tt.method, tt.body, tt.header)
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	// Without StreamResponses requests are answered with plain JSON
+	resp := c.do(context.Background(), http.MethodPost, initializeRequest, nil)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a JSON response, got %q", ct)
+	}
+}
+
+func TestHTTPAllowedOrigins(t *testing.T) {
+	handler := newTestServer(t).NewHTTPHandler(HTTPOptions{AllowedOrigins: []string{"https://app.example.com"}})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://localhost:3000", true},
+		{"http://127.0.0.1", true},
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://evil.example", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "http://app.example.com/mcp", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := handler.validOrigin(r); got != tt.want {
+			t.Errorf("validOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPSessionExpiry(t *testing.T) {
+	handler := newTestServer(t).NewHTTPHandler(HTTPOptions{SessionTimeout: 20 * time.Millisecond})
+	sess := handler.newSession()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		handler.mu.Lock()
+		_, ok := handler.sessions[sess.id]
+		expiring := handler.expiring
+		handler.mu.Unlock()
+		if !ok && !expiring {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Idle session was not expired without new sessions starting")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventStreamRetention(t *testing.T) {
+	stream := newEventStream()
+	for i := 1; i <= maxRetainedEvents+10; i++ {
+		stream.append([]byte(strconv.Itoa(i)))
+	}
+
+	events, from, _, _ := stream.since(0)
+	if len(events) != maxRetainedEvents || from != 10 || string(events[0]) != "11" {
+		t.Errorf("Expected the last %d events after seq 10, got %d after seq %d", maxRetainedEvents, len(events), from)
+	}
+
+	events, from, _, _ = stream.since(maxRetainedEvents + 5)
+	if len(events) != 5 || from != maxRetainedEvents+5 || string(events[0]) != strconv.Itoa(maxRetainedEvents+6) {
+		t.Errorf("Expected 5 events after seq %d, got %d after seq %d", maxRetainedEvents+5, len(events), from)
+	}
+}
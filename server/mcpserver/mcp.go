@@ -0,0 +1,581 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	serverName    = "go-mcp-lsp"
+	serverVersion = "dev"
+)
+
+// supportedProtocolVersions lists the MCP revisions the server speaks, newest
+// first.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Session is the protocol state of one MCP client. Each stdio connection or
+// HTTP session gets its own.
+type Session struct {
+	server *MCPServer
+
+	mu              sync.Mutex
+	initialized     bool
+	protocolVersion string
+	clientInfo      implementation
+}
+
+type implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// NewSession starts the protocol state for a new client.
+func (s *MCPServer) NewSession() *Session {
+	return &Session{server: s}
+}
+
+// ProtocolVersion returns the negotiated protocol version, or "" before
+// initialization.
+func (sess *Session) ProtocolVersion() string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.protocolVersion
+}
+
+// HandleMessage processes one framed JSON-RPC message, which may be a batch,
+// and returns the encoded response. It returns nil when nothing needs to be
+// sent back, as for notifications.
+func (sess *Session) HandleMessage(ctx context.Context, data []byte) []byte {
+	if isBatch(data) {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return encode(newError(nil, CodeParseError, "parse error"))
+		}
+		if len(batch) == 0 {
+			return encode(newError(nil, CodeInvalidRequest, "empty batch"))
+		}
+
+		var responses []*Response
+		for _, raw := range batch {
+			if resp := sess.handleRaw(ctx, raw); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return encode(responses)
+	}
+
+	if resp := sess.handleRaw(ctx, data); resp != nil {
+		return encode(resp)
+	}
+	return nil
+}
+
+func (sess *Session) handleRaw(ctx context.Context, data []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return newError(nil, CodeParseError, "parse error")
+	}
+
+	// Responses to server-initiated requests carry no method; the server
+	// does not send any, so they are dropped.
+	if req.Method == "" {
+		if req.IsNotification() {
+			return newError(nil, CodeInvalidRequest, "invalid request: missing method")
+		}
+		return nil
+	}
+	if req.JSONRPC != "2.0" {
+		return newError(req.ID, CodeInvalidRequest, "invalid request: jsonrpc must be \"2.0\"")
+	}
+
+	return sess.Handle(ctx, &req)
+}
+
+// Handle dispatches a decoded request and returns its response, or nil for
+// notifications.
+func (sess *Session) Handle(ctx context.Context, req *Request) *Response {
+	result, err := sess.dispatch(ctx, req)
+	if req.IsNotification() {
+		return nil
+	}
+	if err != nil {
+		if rpcErr, ok := err.(*RPCError); ok {
+			return &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+		}
+		return newError(req.ID, CodeInternalError, err.Error())
+	}
+	return newResult(req.ID, result)
+}
+
+func (sess *Session) dispatch(ctx context.Context, req *Request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return sess.initialize(req.Params)
+	case "notifications/initialized":
+		sess.mu.Lock()
+		sess.initialized = true
+		sess.mu.Unlock()
+		return nil, nil
+	case "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
+	}
+
+	if sess.ProtocolVersion() == "" {
+		return nil, &RPCError{Code: CodeInvalidRequest, Message: "server not initialized"}
+	}
+
+	switch req.Method {
+	case "tools/list":
+		return map[string]interface{}{"tools": mcpTools}, nil
+	case "tools/call":
+		return sess.server.callTool(ctx, req.Params)
+	case "resources/list":
+		return sess.server.listResources()
+	case "resources/read":
+		return sess.server.readResource(req.Params)
+	case "prompts/list":
+		return sess.server.listPrompts()
+	case "prompts/get":
+		return sess.server.getPrompt(req.Params)
+	}
+
+	return nil, &RPCError{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+func (sess *Session) initialize(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ProtocolVersion string          `json:"protocolVersion"`
+		Capabilities    json.RawMessage `json:"capabilities"`
+		ClientInfo      implementation  `json:"clientInfo"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	// Answer with the requested version when supported, otherwise with the
+	// latest one and let the client decide whether to disconnect.
+	version := supportedProtocolVersions[0]
+	for _, v := range supportedProtocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+
+	sess.mu.Lock()
+	sess.protocolVersion = version
+	sess.clientInfo = p.ClientInfo
+	sess.mu.Unlock()
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{"listChanged": false},
+			"resources": map[string]interface{}{"listChanged": false},
+			"prompts":   map[string]interface{}{"listChanged": false},
+		},
+		"serverInfo": implementation{Name: serverName, Version: serverVersion},
+		"instructions": "Governance rules for Go code. Read rule:// resources for the rules, " +
+			"use the prompts to scaffold compliant code and call validateCode to check code against the rules.",
+	}, nil
+}
+
+// Tools
+
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "validateCode",
+		Description: "Validate Go source code against the governance rules and report every violation with its rule id, severity and position.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"code": map[string]interface{}{"type": "string", "description": "Go source code"},
+				"rules": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Rule ids, aliases or categories; defaults to every rule",
+				},
+				"filename": map[string]interface{}{"type": "string", "description": "File name used in reported positions"},
+			},
+			"required": []string{"code"},
+		},
+	},
+	{
+		Name:        "listRules",
+		Description: "List the governance rules with their description, rationale, category and severity.",
+		InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+	},
+	{
+		Name:        "generateScaffold",
+		Description: "Render a code template, such as go/service, with the given data.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"template": map[string]interface{}{"type": "string", "description": "Template name relative to the templates directory, e.g. go/service"},
+				"data":     map[string]interface{}{"type": "object", "description": "Template fields, e.g. {\"PackageName\": \"orders\"}"},
+			},
+			"required": []string{"template"},
+		},
+	},
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent interface{}   `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+// toolError reports a failure of the tool itself, which MCP delivers as a
+// result so the model can see it, rather than as a protocol error.
+func toolError(format string, args ...interface{}) *toolResult {
+	return &toolResult{
+		Content: []textContent{{Type: "text", Text: fmt.Sprintf(format, args...)}},
+		IsError: true,
+	}
+}
+
+func (s *MCPServer) callTool(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	switch p.Name {
+	case "validateCode":
+		var args struct {
+			Code     string   `json:"code"`
+			Rules    []string `json:"rules"`
+			Filename string   `json:"filename"`
+		}
+		if err := decodeParams(p.Arguments, &args); err != nil {
+			return nil, err
+		}
+
+		rules := args.Rules
+		if len(rules) == 0 {
+			registry, err := s.resources.Registry()
+			if err != nil {
+				return toolError("failed to load rules: %v", err), nil
+			}
+			rules = registry.IDs()
+		}
+
+		issues, err := s.validate(args.Filename, args.Code, rules)
+		if err != nil {
+			return toolError("%v", err), nil
+		}
+
+		var text strings.Builder
+		if len(issues) == 0 {
+			text.WriteString("No governance issues found.")
+		}
+		for _, issue := range issues {
+			if issue.Location != nil {
+				fmt.Fprintf(&text, "%d:%d: ", issue.Location.Line, issue.Location.Column)
+			}
+			fmt.Fprintf(&text, "[%s] %s (%s)\n", issue.RuleID, issue.Description, issue.Severity)
+		}
+
+		return &toolResult{
+			Content: []textContent{{Type: "text", Text: text.String()}},
+			StructuredContent: map[string]interface{}{
+				"valid":  len(issues) == 0,
+				"issues": issues,
+			},
+			IsError: false,
+		}, nil
+
+	case "listRules":
+		registry, err := s.resources.Registry()
+		if err != nil {
+			return toolError("failed to load rules: %v", err), nil
+		}
+
+		var text strings.Builder
+		for _, rule := range registry.Rules() {
+			fmt.Fprintf(&text, "%s (%s, %s): %s\n", rule.ID, rule.Category, rule.Severity, rule.Description)
+		}
+		return &toolResult{
+			Content:           []textContent{{Type: "text", Text: text.String()}},
+			StructuredContent: map[string]interface{}{"rules": registry.Rules()},
+		}, nil
+
+	case "generateScaffold":
+		var args struct {
+			Template string                 `json:"template"`
+			Data     map[string]interface{} `json:"data"`
+		}
+		if err := decodeParams(p.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if args.Template == "" {
+			return toolError("missing template parameter"), nil
+		}
+
+		code, err := s.resources.RenderTemplate(args.Template, args.Data)
+		if err != nil {
+			return toolError("%v", err), nil
+		}
+		return &toolResult{Content: []textContent{{Type: "text", Text: code}}}, nil
+	}
+
+	return nil, &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", p.Name)}
+}
+
+// Resources
+
+type mcpResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+const (
+	ruleScheme     = "rule://"
+	templateScheme = "template://"
+)
+
+func (s *MCPServer) listResources() (interface{}, error) {
+	registry, err := s.resources.Registry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	resources := []mcpResource{}
+	for _, rule := range registry.Rules() {
+		resources = append(resources, mcpResource{
+			URI:         ruleScheme + rule.ID,
+			Name:        rule.ID,
+			Description: rule.Description,
+			MimeType:    "application/yaml",
+		})
+	}
+
+	templates, err := s.templateNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range templates {
+		resources = append(resources, mcpResource{
+			URI:         templateScheme + name,
+			Name:        name,
+			Description: fmt.Sprintf("Code template %s", name),
+			MimeType:    "text/plain",
+		})
+	}
+
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+func (s *MCPServer) readResource(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	var contents resourceContents
+	switch {
+	case strings.HasPrefix(p.URI, ruleScheme):
+		var result Result
+		s.GetResource(strings.TrimPrefix(p.URI, ruleScheme), &result)
+		if !result.Success {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: result.Error}
+		}
+		contents = resourceContents{URI: p.URI, MimeType: "application/yaml", Text: result.Data.(Resource).Content}
+
+	case strings.HasPrefix(p.URI, templateScheme):
+		text, err := s.readTemplate(strings.TrimPrefix(p.URI, templateScheme))
+		if err != nil {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		contents = resourceContents{URI: p.URI, MimeType: "text/plain", Text: text}
+
+	default:
+		return nil, &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("resource not found: %s", p.URI)}
+	}
+
+	return map[string]interface{}{"contents": []resourceContents{contents}}, nil
+}
+
+// Prompts
+
+type mcpPrompt struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Arguments   []mcpPromptArgument `json:"arguments"`
+}
+
+type mcpPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// templateFieldPattern finds the top-level fields a template uses, such as
+// {{.ServiceName}}, which become the prompt's arguments.
+var templateFieldPattern = regexp.MustCompile(`(?:\{\{-?\s*|[\s(])\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+func (s *MCPServer) listPrompts() (interface{}, error) {
+	names, err := s.templateNames()
+	if err != nil {
+		return nil, err
+	}
+
+	prompts := []mcpPrompt{}
+	for _, name := range names {
+		text, err := s.readTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, mcpPrompt{
+			Name:        name,
+			Description: fmt.Sprintf("Scaffold code from the %s template so that it follows the governance rules", name),
+			Arguments:   promptArguments(text),
+		})
+	}
+
+	return map[string]interface{}{"prompts": prompts}, nil
+}
+
+func (s *MCPServer) getPrompt(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	text, err := s.readTemplate(p.Name)
+	if err != nil {
+		return nil, &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown prompt: %s", p.Name)}
+	}
+	for _, arg := range promptArguments(text) {
+		if _, ok := p.Arguments[arg.Name]; !ok {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("missing argument: %s", arg.Name)}
+		}
+	}
+
+	code, err := s.resources.RenderTemplate(p.Name, p.Arguments)
+	if err != nil {
+		return nil, &RPCError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	message := fmt.Sprintf("Write Go code based on the following %s scaffold. "+
+		"Keep its structure and make sure the result passes the validateCode tool.\n\n```go\n%s\n```", p.Name, code)
+
+	return map[string]interface{}{
+		"description": fmt.Sprintf("Scaffold from the %s template", p.Name),
+		"messages": []map[string]interface{}{{
+			"role":    "user",
+			"content": textContent{Type: "text", Text: message},
+		}},
+	}, nil
+}
+
+func promptArguments(text string) []mcpPromptArgument {
+	seen := make(map[string]bool)
+	args := []mcpPromptArgument{}
+	for _, m := range templateFieldPattern.FindAllStringSubmatch(text, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		args = append(args, mcpPromptArgument{Name: m[1], Required: true})
+	}
+	sort.Slice(args, func(i, j int) bool {
+		return args[i].Name < args[j].Name
+	})
+	return args
+}
+
+// templateNames returns the templates under TemplatesDir as slash-separated
+// paths without the .tmpl extension, e.g. go/service.
+func (s *MCPServer) templateNames() ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(s.TemplatesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+		rel, err := filepath.Rel(s.TemplatesDir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(strings.TrimSuffix(rel, ".tmpl")))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return names, nil
+}
+
+func (s *MCPServer) readTemplate(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", fmt.Errorf("template not found: %s", name)
+	}
+
+	var result Result
+	s.GetPrompt(PromptRequest{FileType: filepath.Dir(clean), Identifier: filepath.Base(clean)}, &result)
+	if !result.Success {
+		return "", fmt.Errorf("%s", result.Error)
+	}
+	return result.Data.(string), nil
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
+
+func encode(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(newError(nil, CodeInternalError, err.Error()))
+	}
+	return data
+}
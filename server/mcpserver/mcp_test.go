@@ -0,0 +1,169 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/go-mcp-lsp/pkg/rules"
+)
+
+func newTestServer(t *testing.T) *MCPServer {
+	t.Helper()
+	server, err := NewMCPServer("rules", "templates")
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+	return server
+}
+
+func TestNewMCPServerRejectsDuplicateRules(t *testing.T) {
+	dir := t.TempDir()
+	rule := "id: dup\ndescription: d\nseverity: warning\n"
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(rule), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := NewMCPServer(dir, "templates")
+	var dup *rules.DuplicateRuleError
+	if !errors.As(err, &dup) {
+		t.Fatalf("Expected a duplicate rule error at startup, got %v", err)
+	}
+}
+
+// exchange runs the messages through ServeStdio and returns the responses
+// keyed by request id.
+func exchange(t *testing.T, messages ...string) map[string]map[string]interface{} {
+	t.Helper()
+
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(messages, "\n") + "\n")
+	if err := newTestServer(t).ServeStdio(context.Background(), in, &out); err != nil {
+		t.Fatalf("ServeStdio: %v", err)
+	}
+
+	responses := make(map[string]map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		id, _ := json.Marshal(resp["id"])
+		responses[string(id)] = resp
+	}
+	return responses
+}
+
+const initializeRequest = `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+
+func TestMCPStdio(t *testing.T) {
+	responses := exchange(t,
+		`{"jsonrpc":"2.0","id":"early","method":"tools/list"}`,
+		initializeRequest,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"validateCode","arguments":{"code":"package x\n\nfunc f() {\n\tv, err := g()\n\t_ = v\n}\n","rules":["error_handling"]}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"rule://error_handling"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"prompts/list"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"prompts/get","params":{"name":"go/service","arguments":{"PackageName":"orders","ServiceName":"OrderService"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"unknown/method"}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"validateCode","arguments":{"code":"package x","rules":["no_such_rule"]}}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"tools/call","params":{"name":"generateScaffold","arguments":{"template":"../templates/go/service"}}}`,
+		`{"jsonrpc":"2.0","id":10,"method":"tools/call","params":{"name":"validateCode","arguments":{"code":"package x\n\nimport \"os\"\n\nfunc f() {\n\tos.Remove(\"tmp\")\n}\n","rules":["discarded_errors"]}}}`,
+		`{not json`,
+	)
+
+	tests := []struct {
+		id      string
+		errCode float64
+		want    string
+	}{
+		{`"early"`, CodeInvalidRequest, ""},
+		{`0`, 0, `"protocolVersion":"2025-03-26"`},
+		{`1`, 0, `"name":"validateCode"`},
+		{`2`, 0, `"ruleID":"error_handling"`},
+		{`3`, 0, `"uri":"template://go/service"`},
+		{`4`, 0, `id: error_handling`},
+		{`5`, 0, `"name":"ServiceName"`},
+		{`6`, 0, `type OrderService struct`},
+		{`7`, CodeMethodNotFound, ""},
+		{`8`, 0, `"isError":true`},
+		{`9`, 0, `invalid name`},
+		// Needs type information
+		{`10`, 0, `Error returned by os.Remove is discarded`},
+		{`null`, CodeParseError, ""},
+	}
+
+	for _, tt := range tests {
+		resp, ok := responses[tt.id]
+		if !ok {
+			t.Errorf("no response for id %s", tt.id)
+			continue
+		}
+
+		if tt.errCode != 0 {
+			rpcErr, ok := resp["error"].(map[string]interface{})
+			if !ok || rpcErr["code"] != tt.errCode {
+				t.Errorf("id %s: expected error %v, got %v", tt.id, tt.errCode, resp)
+			}
+			continue
+		}
+
+		result, _ := json.Marshal(resp["result"])
+		if !strings.Contains(string(result), tt.want) {
+			t.Errorf("id %s: result does not contain %s: %s", tt.id, tt.want, result)
+		}
+	}
+
+	if len(responses) != len(tests) {
+		t.Errorf("expected %d responses, got %d", len(tests), len(responses))
+	}
+}
+
+func TestMCPStdioCancel(t *testing.T) {
+	// The client never writes, so only cancellation can end the session
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- newTestServer(t).ServeStdio(ctx, r, io.Discard) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeStdio did not return after cancellation")
+	}
+}
+
+func TestMCPVersionNegotiation(t *testing.T) {
+	sess := newTestServer(t).NewSession()
+
+	resp := sess.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`))
+	if !bytes.Contains(resp, []byte(`"protocolVersion":"`+supportedProtocolVersions[0]+`"`)) {
+		t.Errorf("expected the latest supported version for an unknown one, got %s", resp)
+	}
+
+	batch := sess.HandleMessage(context.Background(), []byte(`[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"}]`))
+	var responses []Response
+	if err := json.Unmarshal(batch, &responses); err != nil || len(responses) != 1 {
+		t.Errorf("expected one batch response, got %s (%v)", batch, err)
+	}
+}
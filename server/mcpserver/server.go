@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"os"
@@ -11,12 +12,15 @@ import (
 	"strings"
 	
 	"github.com/yourorg/go-mcp-lsp/pkg/analyzer"
+	"github.com/yourorg/go-mcp-lsp/server/mcpserver/endpoints"
 )
 
 type MCPServer struct {
 	RulesDir     string
 	TemplatesDir string
 	listener     net.Listener
+	httpServer   *http.Server
+	resources    *endpoints.ResourceManager
 }
 
 type Resource struct {
@@ -58,9 +62,17 @@ func NewMCPServer(rulesDir, templatesDir string) (*MCPServer, error) {
 		return nil, fmt.Errorf("templates directory not found: %w", err)
 	}
 	
+	// Load the rules now so that invalid or duplicate rules stop the server
+	// instead of failing its first request
+	resources := endpoints.NewResourceManager(rulesDir, templatesDir)
+	if _, err := resources.Registry(); err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+	
 	return &MCPServer{
 		RulesDir:     rulesDir,
 		TemplatesDir: templatesDir,
+		resources:    resources,
 	}, nil
 }
 
@@ -87,6 +99,9 @@ func (s *MCPServer) Start(address string) error {
 }
 
 func (s *MCPServer) Stop() error {
+	if s.httpServer != nil {
+		return s.httpServer.Close()
+	}
 	if s.listener != nil {
 		return s.listener.Close()
 	}
@@ -94,8 +109,25 @@ func (s *MCPServer) Stop() error {
 }
 
 func (s *MCPServer) GetResource(id string, result *Result) error {
-	filePath := filepath.Join(s.RulesDir, id+".yaml")
+	registry, err := s.resources.Registry()
+	if err != nil {
+		*result = Result{
+			Success: false,
+			Error:   fmt.Sprintf("failed to load rules: %v", err),
+		}
+		return nil
+	}
+	
+	rule, ok := registry.Lookup(id)
+	if !ok {
+		*result = Result{
+			Success: false,
+			Error:   fmt.Sprintf("resource not found: unknown rule %q", id),
+		}
+		return nil
+	}
 	
+	filePath, _ := registry.Path(rule.ID)
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		*result = Result{
@@ -108,7 +140,7 @@ func (s *MCPServer) GetResource(id string, result *Result) error {
 	*result = Result{
 		Success: true,
 		Data: Resource{
-			ID:      id,
+			ID:      rule.ID,
 			Content: string(data),
 			Type:    "yaml",
 		},
@@ -192,6 +224,24 @@ func (s *MCPServer) CallTool(req ToolRequest, result *Result) error {
 		}
 		return nil
 
+	case "listRules":
+		rules := []map[string]interface{}{}
+		for _, rule := range analyzer.Rules() {
+			meta := rule.Meta()
+			rules = append(rules, map[string]interface{}{
+				"id":          rule.ID(),
+				"description": meta.Description,
+				"category":    meta.Category,
+				"severity":    meta.Severity,
+			})
+		}
+		
+		*result = Result{
+			Success: true,
+			Data:    rules,
+		}
+		return nil
+
 	default:
 		*result = Result{
 			Success: false,
@@ -210,86 +260,114 @@ func (s *MCPServer) ValidateIntent(req ValidateRequest, result *Result) error {
 		return nil
 	}
 	
-	// Use AST-based analyzer for deeper code inspection
-	engine := analyzer.NewAnalyzerEngine()
-	analysisResult, err := engine.Analyze("file.go", []byte(req.Content), req.RuleIDs)
-	
+	issues, err := s.validate("file.go", req.Content, req.RuleIDs)
 	if err != nil {
 		*result = Result{
 			Success: false,
-			Error:   fmt.Sprintf("analysis failed: %v", err),
+			Error:   err.Error(),
 		}
 		return nil
 	}
 	
+	*result = Result{
+		Success: true,
+		Data: map[string]interface{}{
+			"valid":  len(issues) == 0,
+			"issues": issues,
+		},
+	}
+	
+	return nil
+}
+
+// ValidationIssue is a rule violation reported by ValidateIntent and the
+// validateCode tool. Location is nil for findings of the pattern-based
+// fallback, which cannot point at a position.
+type ValidationIssue struct {
+	RuleID      string         `json:"ruleID"`
+	Description string         `json:"description"`
+	Severity    string         `json:"severity"`
+	Location    *IssueLocation `json:"location,omitempty"`
+}
+
+type IssueLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// validate checks Go source against the named rules, which may be ids,
+// aliases or categories.
+func (s *MCPServer) validate(filename, content string, ruleNames []string) ([]ValidationIssue, error) {
+	if filename == "" {
+		filename = "file.go"
+	}
+
+	if _, err := s.resources.Registry(); err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+	
+	ruleIDs, err := s.resources.ResolveRuleIDs(ruleNames)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Use AST-based analyzer for deeper code inspection
+	analysisResult, err := s.resources.Analyze(filename, []byte(content), ruleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	
 	// Map analyzer issues to MCP issues format
-	issues := []map[string]interface{}{}
+	issues := []ValidationIssue{}
 	
 	if analysisResult != nil && len(analysisResult.Issues) > 0 {
 		for _, issue := range analysisResult.Issues {
-			issueMap := map[string]interface{}{
-				"ruleID":      issue.RuleID,
-				"description": issue.Description,
-				"severity":    issue.Severity,
-				"location": map[string]interface{}{
-					"line":   issue.Position.Line,
-					"column": issue.Position.Column,
+			issues = append(issues, ValidationIssue{
+				RuleID:      issue.RuleID,
+				Description: issue.Description,
+				Severity:    issue.Severity,
+				Location: &IssueLocation{
+					Line:   issue.Position.Line,
+					Column: issue.Position.Column,
 				},
-			}
-			issues = append(issues, issueMap)
+			})
 		}
 	} else {
 		// Fallback to simpler pattern-based checks if AST analysis doesn't find issues
 		// This can help catch issues that might be missed by AST analysis
-		issues = performPatternBasedValidation(req.Content, req.RuleIDs)
+		issues = performPatternBasedValidation(content, ruleIDs)
 	}
 	
-	*result = Result{
-		Success: true,
-		Data: map[string]interface{}{
-			"valid":  len(issues) == 0,
-			"issues": issues,
-		},
-	}
-	
-	return nil
+	return issues, nil
 }
 
 // performPatternBasedValidation implements the previous string-based validation logic
-// as a fallback mechanism when AST analysis doesn't find any issues
-func performPatternBasedValidation(content string, ruleIDs []string) []map[string]interface{} {
-	issues := []map[string]interface{}{}
+// as a fallback mechanism when AST analysis doesn't find any issues. Rule IDs must
+// already be resolved through the rule registry.
+func performPatternBasedValidation(content string, ruleIDs []string) []ValidationIssue {
+	issues := []ValidationIssue{}
 	
 	for _, ruleID := range ruleIDs {
-		// Handle subdirectory paths in rule IDs
-		ruleActualID := ruleID
-		
-		// If the rule ID contains a path separator, extract the actual rule ID
-		if strings.Contains(ruleID, "/") {
-			parts := strings.Split(ruleID, "/")
-			ruleActualID = parts[len(parts)-1]
-		}
-		
 		// Basic validation based on the rule ID
-		switch ruleActualID {
+		switch ruleID {
 		case "error_handling":
 			// Check for error handling patterns
 			if strings.Contains(content, "err :=") || strings.Contains(content, "err =") {
 				if !strings.Contains(content, "if err != nil") {
-					issues = append(issues, map[string]interface{}{
-						"ruleID":      "error_handling",
-						"description": "Missing error handling pattern 'if err != nil'",
-						"severity":    "warning",
+					issues = append(issues, ValidationIssue{
+						RuleID:      "error_handling",
+						Description: "Missing error handling pattern 'if err != nil'",
+						Severity:    "warning",
 					})
 				}
 			}
 			
 			// Check for ignored errors
 			if strings.Contains(content, "_ =") && strings.Contains(content, "err") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "error_handling",
-					"description": "Error is being ignored with underscore assignment",
-					"severity":    "error",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "error_handling",
+					Description: "Error is being ignored with underscore assignment",
+					Severity:    "error",
 				})
 			}
 			
@@ -298,33 +376,33 @@ func performPatternBasedValidation(content string, ruleIDs []string) []map[strin
 			if strings.Contains(content, "func ") && 
 				strings.Contains(content, "(*") && 
 				!strings.Contains(content, "ctx context.Context") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "api_design",
-					"description": "API methods should accept context.Context as first parameter",
-					"severity":    "warning",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "api_design",
+					Description: "API methods should accept context.Context as first parameter",
+					Severity:    "warning",
 				})
 			}
 			
-		case "concurrent_map_access", "synchronization":
+		case "concurrent_map_access":
 			// Check for concurrent map access without synchronization
 			if strings.Contains(content, "go func") &&
 				strings.Contains(content, "map[") &&
 				!strings.Contains(content, "sync.Mutex") &&
 				!strings.Contains(content, "sync.RWMutex") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "concurrent_map_access",
-					"description": "Concurrent map access without proper synchronization",
-					"severity":    "error",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "concurrent_map_access",
+					Description: "Concurrent map access without proper synchronization",
+					Severity:    "error",
 				})
 			}
 			
 		case "secure_coding":
 			// Check for weak crypto
 			if strings.Contains(content, "crypto/md5") || strings.Contains(content, "crypto/sha1") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "secure_coding",
-					"description": "Using weak cryptographic algorithms (MD5/SHA1)",
-					"severity":    "error",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "secure_coding",
+					Description: "Using weak cryptographic algorithms (MD5/SHA1)",
+					Severity:    "error",
 				})
 			}
 			
@@ -332,10 +410,10 @@ func performPatternBasedValidation(content string, ruleIDs []string) []map[strin
 			if strings.Contains(content, "fmt.Sprintf") && 
 				strings.Contains(content, "SELECT") &&
 				strings.Contains(content, "%s") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "secure_coding",
-					"description": "Potential SQL injection vulnerability",
-					"severity":    "error",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "secure_coding",
+					Description: "Potential SQL injection vulnerability",
+					Severity:    "error",
 				})
 			}
 			
@@ -345,42 +423,42 @@ func performPatternBasedValidation(content string, ruleIDs []string) []map[strin
 				if strings.Contains(content, pattern) && 
 					strings.Contains(content, "\"") &&
 					!strings.Contains(content, "os.Getenv") {
-					issues = append(issues, map[string]interface{}{
-						"ruleID":      "secure_coding",
-						"description": "Hardcoded credentials detected",
-						"severity":    "error",
+					issues = append(issues, ValidationIssue{
+						RuleID:      "secure_coding",
+						Description: "Hardcoded credentials detected",
+						Severity:    "error",
 					})
 					break
 				}
 			}
 			
-		case "coding_standards", "org_coding_standards":
+		case "org_coding_standards":
 			// Check for global variables
 			if strings.Contains(content, "var ") &&
 				strings.Contains(content, "Global") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "org_coding_standards",
-					"description": "Global variables violate organizational standards",
-					"severity":    "warning",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "org_coding_standards",
+					Description: "Global variables violate organizational standards",
+					Severity:    "warning",
 				})
 			}
 			
 			// Check for snake_case function names
 			if strings.Contains(content, "func do_") || strings.Contains(content, "func get_") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "org_coding_standards",
-					"description": "Snake case function names are not allowed",
-					"severity":    "warning",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "org_coding_standards",
+					Description: "Snake case function names are not allowed",
+					Severity:    "warning",
 				})
 			}
 			
 			// Check for dependency injection patterns
 			if strings.Contains(content, "type Service struct") &&
 				!strings.Contains(content, "Config struct") {
-				issues = append(issues, map[string]interface{}{
-					"ruleID":      "org_coding_standards",
-					"description": "Missing configuration struct for dependency injection",
-					"severity":    "warning",
+				issues = append(issues, ValidationIssue{
+					RuleID:      "org_coding_standards",
+					Description: "Missing configuration struct for dependency injection",
+					Severity:    "warning",
 				})
 			}
 		}
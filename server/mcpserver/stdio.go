@@ -0,0 +1,64 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize bounds a single newline-delimited message on stdio.
+const maxMessageSize = 16 << 20
+
+// ServeStdio speaks MCP over a pair of streams, typically the process's
+// stdin and stdout. Messages are newline-delimited JSON-RPC 2.0 and are
+// processed in order. It returns when r reaches EOF or ctx is cancelled,
+// without waiting for a pending read of r to complete.
+func (s *MCPServer) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	sess := s.NewSession()
+
+	// Read on a goroutine so that cancellation is not held up by a read
+	// blocked on a client that sends nothing
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			line := bytes.Clone(bytes.TrimSpace(scanner.Bytes()))
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if err != nil {
+				return fmt.Errorf("failed to read request: %w", err)
+			}
+			return nil
+		case line = <-lines:
+		}
+
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := sess.HandleMessage(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if _, err := w.Write(append(resp, '\n')); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+}